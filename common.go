@@ -11,6 +11,10 @@ var (
 	Query = sql.Query
 	// Routine represents an SQL routine
 	Routine = sql.Routine
+	// Named creates a named argument
+	Named = sql.Named
+	// Param creates a placeholder for a named argument
+	Param = sql.Param
 )
 
 var (
@@ -38,6 +42,18 @@ type (
 
 	// FileSystem represents the SQL filesystem
 	FileSystem = sql.FileSystem
+
+	// TxOptions holds the transaction options
+	TxOptions = sql.TxOptions
+
+	// Migration represents a migration of the database schema
+	Migration = sql.Migration
+
+	// MigrateOptions holds the options of the migration operations
+	MigrateOptions = sql.MigrateOptions
+
+	// ChecksumError is returned when an applied migration file was changed
+	ChecksumError = sql.ChecksumError
 )
 
 // Querier executes the commands
@@ -53,6 +69,10 @@ type Querier interface {
 	// when no records were found.
 	First(ctx context.Context, q sql.Querier, v interface{}) error
 
+	// Iterate executes the query and returns a cursor that reads the
+	// entities one by one. The cursor must be closed by the caller.
+	Iterate(ctx context.Context, q sql.Querier) (*Cursor, error)
+
 	// Query executes a query that returns rows, typically a SELECT in SQL.
 	// It scans the result into the pointer v. In SQL, you it's usually *sql.Rows.
 	Query(ctx context.Context, q sql.Querier) (*sql.Rows, error)
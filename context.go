@@ -0,0 +1,40 @@
+package orm
+
+import "context"
+
+type (
+	txContextKey      struct{}
+	primaryContextKey struct{}
+)
+
+// SetTxContext returns a copy of the context that carries the given
+// transaction. The Gateway that started the transaction runs all queries
+// with such context within it.
+func SetTxContext(ctx context.Context, tx *GatewayTx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// GetTxContext returns the transaction carried by the context. It returns nil
+// if there is no transaction.
+func GetTxContext(ctx context.Context) *GatewayTx {
+	tx, _ := ctx.Value(txContextKey{}).(*GatewayTx)
+	return tx
+}
+
+// WithoutTx returns a copy of the context that forces the queries to run
+// outside of the transaction carried by the parent context.
+func WithoutTx(ctx context.Context) context.Context {
+	return SetTxContext(ctx, nil)
+}
+
+// UsePrimary returns a copy of the context that forces the read queries to
+// run against the primary database instead of the replicas. It's usually
+// used to read the data that was just written.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func isPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return primary
+}
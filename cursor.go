@@ -0,0 +1,34 @@
+package orm
+
+import (
+	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/orm/dialect/sql/scan"
+)
+
+// Cursor iterates over the result of a query one entity at a time without
+// loading the whole result set into the memory.
+type Cursor struct {
+	rows   *sql.Rows
+	cursor *scan.Cursor
+}
+
+// Next prepares the next entity for reading with the Scan method. It returns
+// false when there are no more entities or an error occurred.
+func (c *Cursor) Next() bool {
+	return c.cursor.Next()
+}
+
+// Scan scans the current entity into the pointer v.
+func (c *Cursor) Scan(v interface{}) error {
+	return c.cursor.Scan(v)
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (c *Cursor) Err() error {
+	return c.rows.Err()
+}
+
+// Close closes the underlying rows. It's safe to call Close more than once.
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
@@ -0,0 +1,278 @@
+package dialect
+
+import (
+	"strconv"
+	"strings"
+)
+
+// limit returns the LIMIT and OFFSET clauses that are shared by the built-in
+// dialects.
+func limit(limit, offset *int) string {
+	var clauses []string
+
+	if limit != nil {
+		clauses = append(clauses, "LIMIT "+strconv.Itoa(*limit))
+	}
+
+	if offset != nil {
+		clauses = append(clauses, "OFFSET "+strconv.Itoa(*offset))
+	}
+
+	return strings.Join(clauses, " ")
+}
+
+// varchar returns the varchar type of the given size or the fallback type if
+// the size is not defined.
+func varchar(size int, fallback string) string {
+	if size > 0 {
+		return "varchar(" + strconv.Itoa(size) + ")"
+	}
+
+	return fallback
+}
+
+type sqlite struct{}
+
+func (sqlite) Name() string { return SQLite }
+
+func (sqlite) Family() string { return SQLite }
+
+func (sqlite) Quote(ident string) string { return "`" + ident + "`" }
+
+func (sqlite) Placeholder() Placeholder { return PlaceholderQuestion }
+
+func (sqlite) Supports(feature Feature) bool {
+	return feature == FeatureReturning
+}
+
+func (sqlite) Upsert() Upsert { return UpsertOnConflict }
+
+func (sqlite) Limit(n, offset *int) string { return limit(n, offset) }
+
+func (sqlite) ColumnType(typ Type, size int) (string, bool) {
+	switch typ {
+	case TypeBool:
+		return "boolean", true
+	case TypeInt8, TypeInt16, TypeInt32, TypeInt64,
+		TypeUint8, TypeUint16, TypeUint32, TypeUint64:
+		return "integer", true
+	case TypeFloat32, TypeFloat64:
+		return "real", true
+	case TypeString:
+		return varchar(size, "text"), true
+	case TypeBytes:
+		return "blob", true
+	case TypeTime:
+		return "datetime", true
+	case TypeJSON:
+		return "json", true
+	default:
+		return "", false
+	}
+}
+
+type mysql struct{}
+
+func (mysql) Name() string { return MySQL }
+
+func (mysql) Family() string { return MySQL }
+
+func (mysql) Quote(ident string) string { return "`" + ident + "`" }
+
+func (mysql) Placeholder() Placeholder { return PlaceholderQuestion }
+
+func (mysql) Supports(feature Feature) bool {
+	return feature == FeatureLock || feature == FeatureSchema
+}
+
+func (mysql) Upsert() Upsert { return UpsertOnDuplicateKey }
+
+func (mysql) Limit(n, offset *int) string { return limit(n, offset) }
+
+func (mysql) ColumnType(typ Type, size int) (string, bool) {
+	switch typ {
+	case TypeBool:
+		return "boolean", true
+	case TypeInt8:
+		return "tinyint", true
+	case TypeInt16:
+		return "smallint", true
+	case TypeInt32:
+		return "int", true
+	case TypeInt64:
+		return "bigint", true
+	case TypeUint8:
+		return "tinyint unsigned", true
+	case TypeUint16:
+		return "smallint unsigned", true
+	case TypeUint32:
+		return "int unsigned", true
+	case TypeUint64:
+		return "bigint unsigned", true
+	case TypeFloat32:
+		return "float", true
+	case TypeFloat64:
+		return "double", true
+	case TypeString:
+		return varchar(size, "varchar(255)"), true
+	case TypeBytes:
+		return "blob", true
+	case TypeTime:
+		return "datetime", true
+	case TypeJSON:
+		return "json", true
+	default:
+		return "", false
+	}
+}
+
+type postgres struct{}
+
+func (postgres) Name() string { return Postgres }
+
+func (postgres) Family() string { return Postgres }
+
+func (postgres) Quote(ident string) string {
+	// the identifier was quoted with the wrong character
+	if strings.Contains(ident, "`") {
+		return strings.ReplaceAll(ident, "`", `"`)
+	}
+
+	return `"` + ident + `"`
+}
+
+func (postgres) Placeholder() Placeholder { return PlaceholderDollar }
+
+func (postgres) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureReturning, FeatureLock, FeatureSchema:
+		return true
+	default:
+		return false
+	}
+}
+
+func (postgres) Upsert() Upsert { return UpsertOnConflict }
+
+func (postgres) Limit(n, offset *int) string { return limit(n, offset) }
+
+func (postgres) ColumnType(typ Type, size int) (string, bool) {
+	switch typ {
+	case TypeBool:
+		return "boolean", true
+	case TypeInt8, TypeInt16, TypeUint8:
+		return "smallint", true
+	case TypeInt32, TypeUint16:
+		return "integer", true
+	case TypeInt64, TypeUint32:
+		return "bigint", true
+	case TypeUint64:
+		return "numeric(20)", true
+	case TypeFloat32:
+		return "real", true
+	case TypeFloat64:
+		return "double precision", true
+	case TypeString:
+		return varchar(size, "varchar"), true
+	case TypeBytes:
+		return "bytea", true
+	case TypeTime:
+		return "timestamp with time zone", true
+	case TypeJSON:
+		return "jsonb", true
+	default:
+		return "", false
+	}
+}
+
+type sqlserver struct{}
+
+func (sqlserver) Name() string { return SQLServer }
+
+func (sqlserver) Family() string { return SQLServer }
+
+func (sqlserver) Quote(ident string) string {
+	// the identifier was quoted with the wrong character
+	if strings.Contains(ident, "`") {
+		var (
+			b    strings.Builder
+			open = true
+		)
+
+		for _, r := range ident {
+			switch {
+			case r == '`' && open:
+				b.WriteRune('[')
+			case r == '`':
+				b.WriteRune(']')
+			default:
+				b.WriteRune(r)
+				continue
+			}
+
+			open = !open
+		}
+
+		return b.String()
+	}
+
+	return "[" + strings.ReplaceAll(ident, "]", "]]") + "]"
+}
+
+func (sqlserver) Placeholder() Placeholder { return PlaceholderAt }
+
+func (sqlserver) Supports(feature Feature) bool {
+	return feature == FeatureSchema
+}
+
+func (sqlserver) Upsert() Upsert { return UpsertMerge }
+
+// Limit returns the OFFSET and FETCH clauses. They must follow the ORDER BY
+// clause.
+func (sqlserver) Limit(limit, offset *int) string {
+	clause := "OFFSET 0 ROWS"
+
+	if offset != nil {
+		clause = "OFFSET " + strconv.Itoa(*offset) + " ROWS"
+	}
+
+	if limit != nil {
+		clause += " FETCH NEXT " + strconv.Itoa(*limit) + " ROWS ONLY"
+	}
+
+	return clause
+}
+
+func (sqlserver) ColumnType(typ Type, size int) (string, bool) {
+	switch typ {
+	case TypeBool:
+		return "bit", true
+	case TypeInt8, TypeInt16:
+		return "smallint", true
+	case TypeInt32, TypeUint16:
+		return "int", true
+	case TypeInt64, TypeUint32:
+		return "bigint", true
+	case TypeUint8:
+		return "tinyint", true
+	case TypeUint64:
+		return "numeric(20)", true
+	case TypeFloat32:
+		return "real", true
+	case TypeFloat64:
+		return "float", true
+	case TypeString:
+		if size > 0 {
+			return "nvarchar(" + strconv.Itoa(size) + ")", true
+		}
+		return "nvarchar(255)", true
+	case TypeBytes:
+		return "varbinary(max)", true
+	case TypeTime:
+		return "datetimeoffset", true
+	case TypeJSON:
+		return "nvarchar(max)", true
+	default:
+		return "", false
+	}
+}
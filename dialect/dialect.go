@@ -6,20 +6,43 @@ package dialect
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"io/fs"
 )
 
 // Dialect names for external usage.
 const (
-	MySQL    = "mysql"
-	SQLite   = "sqlite3"
-	Postgres = "postgres"
+	MySQL     = "mysql"
+	SQLite    = "sqlite3"
+	Postgres  = "postgres"
+	SQLServer = "sqlserver"
 )
 
 // FileSystem represents a file sytem storage
 type FileSystem = fs.FS
 
+// IsolationLevel is the transaction isolation level used in TxOptions.
+type IsolationLevel = sql.IsolationLevel
+
+// TxOptions holds the transaction options to be used in Driver.BeginTx.
+type TxOptions struct {
+	// Isolation is the transaction isolation level.
+	// If zero, the driver or database's default level is used.
+	Isolation IsolationLevel
+	// ReadOnly starts a read-only transaction.
+	ReadOnly bool
+	// Begin overrides the statement that starts the transaction. For
+	// example `BEGIN IMMEDIATE` in SQLite. It cannot be combined with
+	// Isolation and ReadOnly.
+	Begin string
+	// Statements are executed right after the transaction starts. They are
+	// usually used for dialect specific settings like `SET LOCAL` in
+	// PostgreSQL.
+	Statements []string
+}
+
 // ExecQuerier wraps the standard Exec and Query methods.
 type ExecQuerier interface {
 	Execer
@@ -49,14 +72,44 @@ type Driver interface {
 	// Tx starts and returns a new transaction.
 	// The provided context is used until the transaction is committed or rolled back.
 	Tx(context.Context) (Tx, error)
+	// BeginTx starts and returns a new transaction with the given options.
+	// The provided context is used until the transaction is committed or rolled back.
+	BeginTx(context.Context, *TxOptions) (Tx, error)
 	// Migrate runs the migrations
 	Migrate(FileSystem) error
+	// MigrateTo runs the pending migrations up to the given version and
+	// reverts the applied migrations newer than it. It returns the
+	// migrations that were run or reverted.
+	MigrateTo(FileSystem, string, *MigrateOptions) ([]*Migration, error)
+	// Rollback reverts the given number of applied migrations, starting
+	// from the latest. If the steps are negative, it reverts all of them.
+	Rollback(FileSystem, int, *MigrateOptions) ([]*Migration, error)
+	// MigrationStatus returns all applied and pending migrations.
+	MigrationStatus(FileSystem) ([]*Migration, error)
 	// Ping sends a ping request
 	Ping(context.Context) error
 	// Close closes the underlying connection.
 	Close() error
 }
 
+// Validator is implemented by the drivers that can check a query without
+// executing it.
+type Validator interface {
+	// Validate prepares the query and closes the statement.
+	Validate(ctx context.Context, query string) error
+}
+
+// Validate checks the query with the given driver. It returns an error if the
+// driver does not implement Validator.
+func Validate(ctx context.Context, d Driver, query string) error {
+	validator, ok := d.(Validator)
+	if !ok {
+		return fmt.Errorf("dialect: the driver %T cannot validate queries", d)
+	}
+
+	return validator.Validate(ctx, query)
+}
+
 // Tx wraps the Exec and Query operations in transaction.
 type Tx interface {
 	// ExecQuerier inheritance
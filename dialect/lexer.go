@@ -0,0 +1,346 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind is the kind of a SQL token.
+type TokenKind int
+
+const (
+	// TokenPunct is an operator or a punctuation mark (e.g. =, ::, ?| or ;).
+	TokenPunct TokenKind = iota
+	// TokenSpace is a sequence of white spaces.
+	TokenSpace
+	// TokenComment is a line (--) or a block (/* */) comment.
+	TokenComment
+	// TokenWord is a keyword or an unquoted identifier.
+	TokenWord
+	// TokenIdent is a quoted identifier.
+	TokenIdent
+	// TokenString is a string literal or a PostgreSQL dollar-quoted string.
+	TokenString
+	// TokenNumber is a numeric literal.
+	TokenNumber
+	// TokenNamed is a named parameter like :name.
+	TokenNamed
+	// TokenPositional is a positional parameter (?).
+	TokenPositional
+	// TokenEscape is an escaped question mark (??).
+	TokenEscape
+	// TokenParam is a parameter in the syntax of the driver like $1 or @p1.
+	TokenParam
+)
+
+// Token is a part of a SQL query.
+type Token struct {
+	// Kind is the kind of the token.
+	Kind TokenKind
+	// Raw is the text of the token in the query.
+	Raw string
+	// Name is the name of the named parameter.
+	Name string
+}
+
+// Tokenize splits the query of the given dialect into tokens. The tokens
+// joined together are the query. The PostgreSQL casts (::) and the JSON
+// operators (?| and ?&) are not parameters.
+//
+// The backslash escapes the next character only in the MySQL strings and in
+// the PostgreSQL escape strings (E'...'). The double quotes delimit a string
+// in MySQL and an identifier in the other dialects. The block comments are
+// nested only in PostgreSQL. An unknown dialect is tokenized as standard SQL.
+//
+// It returns an error for the first string, identifier, comment or
+// dollar-quoted string that is not terminated. The rest of the query is
+// returned as a single token of the same kind.
+func Tokenize(query, dialect string) ([]Token, error) {
+	var (
+		tokens = []Token{}
+		family = FamilyOf(dialect)
+		err    error
+	)
+
+	// emit appends the token from the start up to the given end. The end is
+	// negative if the token is not terminated.
+	emit := func(kind TokenKind, start, end int, name string) int {
+		if end < 0 {
+			if err == nil {
+				err = fmt.Errorf("dialect: unterminated %s at position %d", describe(kind, query[start]), start)
+			}
+
+			end = len(query)
+		}
+
+		tokens = append(tokens, Token{Kind: kind, Raw: query[start:end], Name: name})
+		return end
+	}
+
+	for index := 0; index < len(query); {
+		var (
+			ch, size = utf8.DecodeRuneInString(query[index:])
+			next     = byteAt(query, index+size)
+		)
+
+		switch {
+		case unicode.IsSpace(ch):
+			index = emit(TokenSpace, index, skipSpace(query, index), "")
+		case ch == '\'':
+			index = emit(TokenString, index, skipQuoted(query, index, '\'', family == MySQL), "")
+		case ch == '"' && family == MySQL:
+			index = emit(TokenString, index, skipQuoted(query, index, '"', true), "")
+		case ch == '"':
+			index = emit(TokenIdent, index, skipQuoted(query, index, '"', false), "")
+		case (ch == 'E' || ch == 'e') && next == '\'' && family == Postgres:
+			index = emit(TokenString, index, skipQuoted(query, index+1, '\'', true), "")
+		case ch == '`':
+			index = emit(TokenIdent, index, skipQuoted(query, index, '`', false), "")
+		case ch == '-' && next == '-':
+			index = emit(TokenComment, index, skipLine(query, index), "")
+		case ch == '/' && next == '*':
+			index = emit(TokenComment, index, skipComment(query, index, family == Postgres), "")
+		case ch == '$' && isDigit(next):
+			index = emit(TokenParam, index, skipDigits(query, index+1), "")
+		case ch == '$':
+			if end, ok := skipDollar(query, index); ok {
+				index = emit(TokenString, index, end, "")
+			} else {
+				index = emit(TokenPunct, index, index+1, "")
+			}
+		case ch == '@' && isNameStart(next):
+			index = emit(TokenParam, index, skipName(query, index+1), "")
+		case ch == ':' && next == ':':
+			index = emit(TokenPunct, index, index+2, "")
+		case ch == ':':
+			if name := nameAt(query, index+1); name != "" {
+				index = emit(TokenNamed, index, index+1+len(name), name)
+			} else {
+				index = emit(TokenPunct, index, index+1, "")
+			}
+		case ch == '?' && next == '?':
+			index = emit(TokenEscape, index, index+2, "")
+		case ch == '?' && (next == '|' || next == '&'):
+			index = emit(TokenPunct, index, index+2, "")
+		case ch == '?':
+			index = emit(TokenPositional, index, index+1, "")
+		case unicode.IsDigit(ch):
+			index = emit(TokenNumber, index, skipNumber(query, index), "")
+		case ch == '_' || unicode.IsLetter(ch):
+			index = emit(TokenWord, index, skipWord(query, index), "")
+		default:
+			index = emit(TokenPunct, index, index+size, "")
+		}
+	}
+
+	return tokens, err
+}
+
+// describe returns the description of the unterminated token.
+func describe(kind TokenKind, quote byte) string {
+	switch {
+	case kind == TokenComment:
+		return "block comment"
+	case kind == TokenIdent:
+		return "quoted identifier"
+	case quote == '$':
+		return "dollar-quoted string"
+	default:
+		return "quoted string"
+	}
+}
+
+func byteAt(query string, index int) byte {
+	if index < len(query) {
+		return query[index]
+	}
+
+	return 0
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || ch >= utf8.RuneSelf || unicode.IsLetter(rune(ch))
+}
+
+func isNameRune(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_'
+}
+
+// skipSpace returns the position after the white spaces.
+func skipSpace(query string, index int) int {
+	for index < len(query) {
+		ch, size := utf8.DecodeRuneInString(query[index:])
+		if !unicode.IsSpace(ch) {
+			break
+		}
+
+		index += size
+	}
+
+	return index
+}
+
+// skipDigits returns the position after the digits.
+func skipDigits(query string, index int) int {
+	for index < len(query) && isDigit(query[index]) {
+		index++
+	}
+
+	return index
+}
+
+// skipNumber returns the position after the numeric literal.
+func skipNumber(query string, index int) int {
+	for index < len(query) && (isDigit(query[index]) || query[index] == '.') {
+		index++
+	}
+
+	return index
+}
+
+// skipName returns the position after the name that starts at the given
+// position.
+func skipName(query string, index int) int {
+	for index < len(query) {
+		ch, size := utf8.DecodeRuneInString(query[index:])
+		if !isNameRune(ch) {
+			break
+		}
+
+		index += size
+	}
+
+	return index
+}
+
+// skipWord returns the position after the keyword or the identifier. The
+// dollar sign can be a part of an identifier.
+func skipWord(query string, index int) int {
+	for index < len(query) {
+		ch, size := utf8.DecodeRuneInString(query[index:])
+		if !isNameRune(ch) && ch != '$' {
+			break
+		}
+
+		index += size
+	}
+
+	return index
+}
+
+// skipQuoted returns the position after the quoted string or identifier that
+// starts at the given position. The quote is escaped by doubling it or by a
+// backslash if the backslash escapes are allowed. It returns -1 if the string
+// is not terminated.
+func skipQuoted(query string, index int, quote byte, backslash bool) int {
+	for index++; index < len(query); index++ {
+		switch query[index] {
+		case '\\':
+			if backslash {
+				index++
+			}
+		case quote:
+			if byteAt(query, index+1) != quote {
+				return index + 1
+			}
+
+			index++
+		}
+	}
+
+	return -1
+}
+
+// skipLine returns the position after the line comment.
+func skipLine(query string, index int) int {
+	if end := strings.IndexByte(query[index:], '\n'); end >= 0 {
+		return index + end + 1
+	}
+
+	return len(query)
+}
+
+// skipComment returns the position after the block comment. The block
+// comments can be nested only in PostgreSQL. It returns -1 if the comment is
+// not terminated.
+func skipComment(query string, index int, nested bool) int {
+	depth := 0
+
+	for index < len(query) {
+		switch {
+		case strings.HasPrefix(query[index:], "/*") && (nested || depth == 0):
+			depth++
+			index += 2
+		case strings.HasPrefix(query[index:], "*/"):
+			depth--
+			index += 2
+
+			if depth == 0 {
+				return index
+			}
+		default:
+			index++
+		}
+	}
+
+	return -1
+}
+
+// skipDollar returns the position after the PostgreSQL dollar-quoted string
+// ($$...$$ or $tag$...$tag$) that starts at the given position. It reports
+// false if there is no valid tag at the position. The position is -1 if the
+// string is not terminated.
+func skipDollar(query string, index int) (int, bool) {
+	end := strings.IndexByte(query[index+1:], '$')
+	if end < 0 {
+		return 0, false
+	}
+
+	tag := query[index : index+end+2]
+
+	for i, ch := range tag[1 : len(tag)-1] {
+		if !isNameRune(ch) || (i == 0 && unicode.IsDigit(ch)) {
+			return 0, false
+		}
+	}
+
+	if close := strings.Index(query[index+len(tag):], tag); close >= 0 {
+		return index + len(tag) + close + len(tag), true
+	}
+
+	return -1, true
+}
+
+// nameAt returns the name of the parameter that starts at the given position.
+// The name starts with a letter or an underscore. The dotted names like
+// user.group.id are paths into nested structs and maps.
+func nameAt(query string, index int) string {
+	end := index
+
+	for end < len(query) {
+		ch, size := utf8.DecodeRuneInString(query[end:])
+
+		switch {
+		case ch == '.' && end > index:
+			// the dot must be followed by a name
+			if next, _ := utf8.DecodeRuneInString(query[end+1:]); isNameRune(next) && !unicode.IsDigit(next) {
+				end += size
+				continue
+			}
+
+			return query[index:end]
+		case !isNameRune(ch) || (end == index && unicode.IsDigit(ch)):
+			return query[index:end]
+		}
+
+		end += size
+	}
+
+	return query[index:end]
+}
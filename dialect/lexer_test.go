@@ -0,0 +1,55 @@
+package dialect_test
+
+import (
+	"github.com/phogolabs/orm/dialect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tokenize", func() {
+	It("splits the query into tokens", func() {
+		tokens, err := dialect.Tokenize("SELECT 'a''b' FROM t WHERE id = :id", "")
+		Expect(err).To(BeNil())
+		Expect(tokens).To(Equal([]dialect.Token{
+			{Kind: dialect.TokenWord, Raw: "SELECT"},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenString, Raw: "'a''b'"},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenWord, Raw: "FROM"},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenWord, Raw: "t"},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenWord, Raw: "WHERE"},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenWord, Raw: "id"},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenPunct, Raw: "="},
+			{Kind: dialect.TokenSpace, Raw: " "},
+			{Kind: dialect.TokenNamed, Raw: ":id", Name: "id"},
+		}))
+	})
+
+	DescribeTable("tokenizes the strings of the dialect",
+		func(name, query string, token dialect.Token) {
+			tokens, err := dialect.Tokenize(query, name)
+			Expect(err).To(BeNil())
+			Expect(tokens).To(ContainElement(token))
+		},
+		Entry("standard string", "", `'C:\' AND`, dialect.Token{Kind: dialect.TokenString, Raw: `'C:\'`}),
+		Entry("standard identifier", "", `"a\" AND`, dialect.Token{Kind: dialect.TokenIdent, Raw: `"a\"`}),
+		Entry("mysql string", dialect.MySQL, `'it\'s' AND`, dialect.Token{Kind: dialect.TokenString, Raw: `'it\'s'`}),
+		Entry("mysql double-quoted string", dialect.MySQL, `"a \" b" AND`, dialect.Token{Kind: dialect.TokenString, Raw: `"a \" b"`}),
+		Entry("postgres string", dialect.Postgres, `'C:\' AND`, dialect.Token{Kind: dialect.TokenString, Raw: `'C:\'`}),
+		Entry("postgres escape string", dialect.Postgres, `E'it\'s' AND`, dialect.Token{Kind: dialect.TokenString, Raw: `E'it\'s'`}),
+		Entry("sqlite string", dialect.SQLite, `'C:\' AND`, dialect.Token{Kind: dialect.TokenString, Raw: `'C:\'`}),
+	)
+
+	Context("when the string is not terminated", func() {
+		It("returns an error", func() {
+			tokens, err := dialect.Tokenize("SELECT 'a", "")
+			Expect(err).To(MatchError("dialect: unterminated quoted string at position 7"))
+			Expect(tokens[len(tokens)-1]).To(Equal(dialect.Token{Kind: dialect.TokenString, Raw: "'a"}))
+		})
+	})
+})
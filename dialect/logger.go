@@ -3,7 +3,11 @@ package dialect
 import (
 	"context"
 	"crypto/rand"
+	"database/sql"
 	"encoding/base64"
+	mrand "math/rand/v2"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/phogolabs/log"
@@ -12,74 +16,186 @@ import (
 // Logger represents a logger
 type Logger = log.Logger
 
+// LogLevel represents the level of a log entry
+type LogLevel = log.Level
+
+// LoggerConfig configures how the LoggerDriver logs the statements.
+type LoggerConfig struct {
+	// Level is the level of the successful statements.
+	Level LogLevel
+	// SampleRate is the fraction of the successful statements that are
+	// logged. All statements are logged if it's zero.
+	SampleRate float64
+	// SlowThreshold is the duration after which a statement is logged as
+	// slow at warn level regardless of the sample rate. Zero disables it.
+	SlowThreshold time.Duration
+	// Redact contains the names or the patterns of the names of the
+	// arguments whose values are masked. The arguments marked as Sensitive
+	// are always masked.
+	Redact []string
+}
+
+// the default configuration logs all statements at info level
+var defaultLoggerConfig = &LoggerConfig{Level: log.InfoLevel}
+
+// slow reports whether the statement that took the given duration is slow.
+func (c *LoggerConfig) slow(duration time.Duration) bool {
+	return c.SlowThreshold > 0 && duration >= c.SlowThreshold
+}
+
+// sample reports whether the successful statement should be logged.
+func (c *LoggerConfig) sample() bool {
+	return c.SampleRate <= 0 || mrand.Float64() < c.SampleRate
+}
+
+// redact returns a copy of the arguments where the sensitive values are
+// masked. The arguments at the positions carried by the context are
+// sensitive too.
+func (c *LoggerConfig) redact(ctx context.Context, args interface{}) interface{} {
+	argv, ok := args.([]interface{})
+	if !ok {
+		return args
+	}
+
+	var (
+		values    = make([]interface{}, len(argv))
+		positions = make(map[int]bool)
+	)
+
+	for _, position := range GetSensitiveContext(ctx) {
+		positions[position] = true
+	}
+
+	for index, arg := range argv {
+		switch value := arg.(type) {
+		case SensitiveValue:
+			values[index] = redacted
+		case sql.NamedArg:
+			if _, ok := value.Value.(SensitiveValue); ok || positions[index] || c.sensitive(value.Name) {
+				value.Value = redacted
+			}
+			values[index] = value
+		default:
+			if positions[index] {
+				values[index] = redacted
+			} else {
+				values[index] = arg
+			}
+		}
+	}
+
+	return values
+}
+
+// sensitive reports whether the argument with the given name is sensitive.
+func (c *LoggerConfig) sensitive(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, pattern := range c.Redact {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 // LoggerDriver is a driver that logs all driver operations.
 type LoggerDriver struct {
 	Driver
 	logger Logger
+	config *LoggerConfig
 }
 
 // Log gets a driver and an optional logging function, and returns
 // a new debugged-driver that prints all outgoing operations.
 func Log(d Driver, logger Logger) Driver {
-	return &LoggerDriver{d, logger}
+	return LogWith(d, logger, defaultLoggerConfig)
+}
+
+// LogWith is like Log, but it logs the operations according to the given
+// configuration. The default configuration is used if it's nil.
+func LogWith(d Driver, logger Logger, config *LoggerConfig) Driver {
+	if config == nil {
+		config = defaultLoggerConfig
+	}
+
+	return &LoggerDriver{d, logger, config}
 }
 
 // Exec logs its params and calls the underlying driver Exec method.
 func (d *LoggerDriver) Exec(ctx context.Context, query string, args, v interface{}) error {
-	var (
-		start  = time.Now()
-		logger = d.logger
-	)
-
+	start := time.Now()
 	err := d.Driver.Exec(ctx, query, args, v)
-
-	logger = logger.WithField("sql.query", query)
-	logger = logger.WithField("sql.param", args)
-	logger = logger.WithField("sql.duration", time.Since(start).String())
-
-	if err != nil {
-		logger.WithError(err).Errorf("query.exec fail")
-		return err
-	}
-
-	logger.Infof("query.exec success")
-	return nil
+	return logQuery(ctx, contextual(ctx, d.logger), d.config, start, query, args, err)
 }
 
 // Query logs its params and calls the underlying driver Query method.
 func (d *LoggerDriver) Query(ctx context.Context, query string, args, v interface{}) error {
-	var (
-		start  = time.Now()
-		logger = d.logger
-	)
-
+	start := time.Now()
 	err := d.Driver.Query(ctx, query, args, v)
+	return logQuery(ctx, contextual(ctx, d.logger), d.config, start, query, args, err)
+}
 
-	logger = logger.WithField("sql.query", query)
-	logger = logger.WithField("sql.param", args)
-	logger = logger.WithField("sql.duration", time.Since(start).String())
-
-	if err != nil {
-		logger.WithError(err).Errorf("query.exec fail")
-		return err
-	}
+// Unwrap returns the underlying driver.
+func (d *LoggerDriver) Unwrap() Driver {
+	return d.Driver
+}
 
-	logger.Infof("query.exec success")
-	return nil
+// Validate calls the underlying driver Validate method.
+func (d *LoggerDriver) Validate(ctx context.Context, query string) error {
+	return Validate(ctx, d.Driver, query)
 }
 
 // Tx adds an log-id for the transaction and calls the underlying driver Tx command.
 func (d *LoggerDriver) Tx(ctx context.Context) (Tx, error) {
-	logger := d.logger.WithField("sql.tx", d.random())
+	return d.BeginTx(ctx, nil)
+}
+
+// BeginTx adds an log-id for the transaction and calls the underlying driver BeginTx command.
+func (d *LoggerDriver) BeginTx(ctx context.Context, opts *TxOptions) (Tx, error) {
+	logger := contextual(ctx, d.logger).WithField("sql.tx", d.random())
 
-	tx, err := d.Driver.Tx(ctx)
+	if opts != nil {
+		logger = logger.WithField("sql.tx.isolation", opts.Isolation.String())
+		logger = logger.WithField("sql.tx.read_only", opts.ReadOnly)
+	}
+
+	tx, err := d.Driver.BeginTx(ctx, opts)
 	if err != nil {
 		logger.WithError(err).Errorf("tx.start fail")
 		return nil, err
 	}
 
 	logger.Infof("tx.start success")
-	return &LoggerTx{tx, logger, ctx}, nil
+	return &LoggerTx{tx, logger, d.config, ctx}, nil
+}
+
+// contextual returns the logger with the sql fields of the context logger
+// (e.g. the attempt of a retried transaction).
+func contextual(ctx context.Context, logger Logger) Logger {
+	type FieldLogger interface {
+		Fields() log.Map
+	}
+
+	source, ok := log.GetContext(ctx).(FieldLogger)
+	if !ok {
+		return logger
+	}
+
+	fields := log.Map{}
+
+	for key, value := range source.Fields() {
+		if strings.HasPrefix(key, "sql.") {
+			fields[key] = value
+		}
+	}
+
+	if len(fields) == 0 {
+		return logger
+	}
+
+	return logger.WithFields(fields)
 }
 
 func (d *LoggerDriver) random() string {
@@ -99,51 +215,22 @@ func (d *LoggerDriver) random() string {
 type LoggerTx struct {
 	Tx                     // underlying transaction.
 	logger Logger          // log function. defaults to fmt.Println.
+	config *LoggerConfig   // log configuration.
 	ctx    context.Context // underlying transaction context.
 }
 
 // Exec logs its params and calls the underlying transaction Exec method.
 func (d *LoggerTx) Exec(ctx context.Context, query string, args, v interface{}) error {
-	var (
-		start  = time.Now()
-		logger = d.logger
-	)
-
+	start := time.Now()
 	err := d.Tx.Exec(ctx, query, args, v)
-
-	logger = logger.WithField("sql.query", query)
-	logger = logger.WithField("sql.param", args)
-	logger = logger.WithField("sql.duration", time.Since(start).String())
-
-	if err != nil {
-		logger.WithError(err).Errorf("query.exec fail")
-		return err
-	}
-
-	logger.Infof("query.exec success")
-	return nil
+	return logQuery(ctx, d.logger, d.config, start, query, args, err)
 }
 
 // Query logs its params and calls the underlying transaction Query method.
 func (d *LoggerTx) Query(ctx context.Context, query string, args, v interface{}) error {
-	var (
-		start  = time.Now()
-		logger = d.logger
-	)
-
+	start := time.Now()
 	err := d.Tx.Query(ctx, query, args, v)
-
-	logger = logger.WithField("sql.query", query)
-	logger = logger.WithField("sql.param", args)
-	logger = logger.WithField("sql.duration", time.Since(start).String())
-
-	if err != nil {
-		logger.WithError(err).Errorf("query.exec fail")
-		return err
-	}
-
-	logger.Infof("query.exec success")
-	return nil
+	return logQuery(ctx, d.logger, d.config, start, query, args, err)
 }
 
 // Commit logs this step and calls the underlying transaction Commit method.
@@ -179,3 +266,40 @@ func (d *LoggerTx) Rollback() error {
 	logger.Infof("tx.rollback success")
 	return nil
 }
+
+// logQuery logs the executed statement according to the configuration.
+func logQuery(ctx context.Context, logger Logger, config *LoggerConfig, start time.Time, query string, args interface{}, err error) error {
+	duration := time.Since(start)
+
+	logger = logger.WithField("sql.query", query)
+	logger = logger.WithField("sql.param", config.redact(ctx, args))
+	logger = logger.WithField("sql.duration", duration.String())
+
+	if err != nil {
+		logger.WithError(err).Errorf("query.exec fail")
+		return err
+	}
+
+	// the slow statements are logged regardless of the sample rate
+	if config.slow(duration) {
+		logger.Warnf("query.exec slow")
+		return nil
+	}
+
+	if !config.sample() {
+		return nil
+	}
+
+	switch config.Level {
+	case log.DebugLevel:
+		logger.Debugf("query.exec success")
+	case log.NoticeLevel:
+		logger.Noticef("query.exec success")
+	case log.WarnLevel:
+		logger.Warnf("query.exec success")
+	default:
+		logger.Infof("query.exec success")
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package dialect_test
+
+import (
+	"context"
+	stdsql "database/sql"
+	"time"
+
+	"github.com/phogolabs/log"
+	"github.com/phogolabs/log/fake"
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoggerDriver", func() {
+	var (
+		ctx     context.Context
+		db      *sql.Driver
+		handler *fake.Handler
+		logger  log.Logger
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		ctx = context.TODO()
+		handler = &fake.Handler{}
+		logger = log.New(&log.Config{Handler: handler})
+
+		db, err = sql.Open("sqlite3", "file:logger.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+		Expect(db.Exec(ctx, "CREATE TABLE users (id int, password text)", []interface{}{}, nil)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(db.Exec(ctx, "DROP TABLE users", []interface{}{}, nil)).To(Succeed())
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("logs the statements at info level", func() {
+		driver := dialect.Log(db, logger)
+		Expect(driver.Exec(ctx, "INSERT INTO users VALUES (?, ?)", []interface{}{1, "secret"}, nil)).To(Succeed())
+
+		Expect(handler.HandleCallCount()).To(Equal(1))
+
+		entry := handler.HandleArgsForCall(0)
+		Expect(entry.Level).To(Equal(log.InfoLevel))
+		Expect(entry.Message).To(Equal("query.exec success"))
+		Expect(entry.Fields).To(HaveKeyWithValue("sql.query", "INSERT INTO users VALUES (?, ?)"))
+		Expect(entry.Fields).To(HaveKeyWithValue("sql.param", []interface{}{1, "secret"}))
+	})
+
+	It("logs the statements at the configured level", func() {
+		driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{Level: log.DebugLevel})
+		Expect(driver.Exec(ctx, "DELETE FROM users", []interface{}{}, nil)).To(Succeed())
+
+		Expect(handler.HandleCallCount()).To(Equal(1))
+		Expect(handler.HandleArgsForCall(0).Level).To(Equal(log.DebugLevel))
+	})
+
+	It("logs the slow statements at warn level", func() {
+		driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{
+			Level:         log.DebugLevel,
+			SampleRate:    0.000001,
+			SlowThreshold: time.Nanosecond,
+		})
+		Expect(driver.Exec(ctx, "DELETE FROM users", []interface{}{}, nil)).To(Succeed())
+
+		Expect(handler.HandleCallCount()).To(Equal(1))
+
+		entry := handler.HandleArgsForCall(0)
+		Expect(entry.Level).To(Equal(log.WarnLevel))
+		Expect(entry.Message).To(Equal("query.exec slow"))
+	})
+
+	It("logs the fast statements at the configured warn level", func() {
+		driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{
+			Level:         log.WarnLevel,
+			SlowThreshold: time.Hour,
+		})
+		Expect(driver.Exec(ctx, "DELETE FROM users", []interface{}{}, nil)).To(Succeed())
+
+		Expect(handler.HandleCallCount()).To(Equal(1))
+
+		entry := handler.HandleArgsForCall(0)
+		Expect(entry.Level).To(Equal(log.WarnLevel))
+		Expect(entry.Message).To(Equal("query.exec success"))
+	})
+
+	Context("when the configuration is nil", func() {
+		It("logs the statements at info level", func() {
+			driver := dialect.LogWith(db, logger, nil)
+			Expect(driver.Exec(ctx, "DELETE FROM users", []interface{}{}, nil)).To(Succeed())
+
+			Expect(handler.HandleCallCount()).To(Equal(1))
+			Expect(handler.HandleArgsForCall(0).Level).To(Equal(log.InfoLevel))
+		})
+	})
+
+	It("samples the successful statements", func() {
+		driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{
+			Level:      log.InfoLevel,
+			SampleRate: 0.000001,
+		})
+
+		for i := 0; i < 10; i++ {
+			Expect(driver.Exec(ctx, "DELETE FROM users", []interface{}{}, nil)).To(Succeed())
+		}
+
+		Expect(handler.HandleCallCount()).To(Equal(0))
+	})
+
+	It("logs all failed statements", func() {
+		driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{
+			Level:      log.InfoLevel,
+			SampleRate: 0.000001,
+		})
+		Expect(driver.Exec(ctx, "DELETE FROM unknown", []interface{}{}, nil)).To(MatchError("no such table: unknown"))
+
+		Expect(handler.HandleCallCount()).To(Equal(1))
+
+		entry := handler.HandleArgsForCall(0)
+		Expect(entry.Level).To(Equal(log.ErrorLevel))
+		Expect(entry.Message).To(Equal("query.exec fail"))
+	})
+
+	It("redacts the sensitive arguments", func() {
+		driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{
+			Level:  log.InfoLevel,
+			Redact: []string{"*token"},
+		})
+
+		args := []interface{}{
+			stdsql.Named("id", 1),
+			stdsql.Named("password", dialect.Sensitive("secret")),
+			stdsql.Named("api_Token", "token"),
+		}
+
+		Expect(driver.Exec(ctx, "INSERT INTO users VALUES (:id, :password)", args[:2], nil)).To(Succeed())
+
+		rows := &sql.Rows{}
+		Expect(driver.Query(ctx, "SELECT * FROM users WHERE id = :id AND :api_Token <> ''", []interface{}{args[0], args[2]}, rows)).To(Succeed())
+		Expect(rows.Close()).To(Succeed())
+
+		Expect(driver.Exec(ctx, "INSERT INTO users VALUES (?, ?)", []interface{}{2, dialect.Sensitive("secret")}, nil)).To(Succeed())
+
+		Expect(handler.HandleCallCount()).To(Equal(3))
+		Expect(handler.HandleArgsForCall(0).Fields).To(HaveKeyWithValue("sql.param", []interface{}{
+			stdsql.Named("id", 1),
+			stdsql.Named("password", "[REDACTED]"),
+		}))
+		Expect(handler.HandleArgsForCall(1).Fields).To(HaveKeyWithValue("sql.param", []interface{}{
+			stdsql.Named("id", 1),
+			stdsql.Named("api_Token", "[REDACTED]"),
+		}))
+		Expect(handler.HandleArgsForCall(2).Fields).To(HaveKeyWithValue("sql.param", []interface{}{2, "[REDACTED]"}))
+
+		var count int
+
+		rows = &sql.Rows{}
+		Expect(db.Query(ctx, "SELECT COUNT(*) FROM users WHERE password = 'secret'", []interface{}{}, rows)).To(Succeed())
+		Expect(rows.Next()).To(BeTrue())
+		Expect(rows.Scan(&count)).To(Succeed())
+		Expect(rows.Close()).To(Succeed())
+		Expect(count).To(Equal(2))
+	})
+
+	It("redacts the arguments at the positions carried by the context", func() {
+		driver := dialect.Log(db, logger)
+
+		ctx := dialect.SetSensitiveContext(ctx, []int{1})
+		Expect(driver.Exec(ctx, "INSERT INTO users VALUES (?, ?)", []interface{}{1, "secret"}, nil)).To(Succeed())
+
+		Expect(handler.HandleCallCount()).To(Equal(1))
+		Expect(handler.HandleArgsForCall(0).Fields).To(HaveKeyWithValue("sql.param", []interface{}{1, "[REDACTED]"}))
+	})
+
+	Context("when the statement is executed in a transaction", func() {
+		It("logs the statements according to the configuration", func() {
+			driver := dialect.LogWith(db, logger, &dialect.LoggerConfig{
+				Level:  log.DebugLevel,
+				Redact: []string{"password"},
+			})
+
+			tx, err := driver.Tx(ctx)
+			Expect(err).To(Succeed())
+
+			args := []interface{}{stdsql.Named("id", 1), stdsql.Named("password", "secret")}
+			Expect(tx.Exec(ctx, "INSERT INTO users VALUES (:id, :password)", args, nil)).To(Succeed())
+			Expect(tx.Commit()).To(Succeed())
+
+			Expect(handler.HandleCallCount()).To(Equal(3))
+
+			entry := handler.HandleArgsForCall(1)
+			Expect(entry.Level).To(Equal(log.DebugLevel))
+			Expect(entry.Fields).To(HaveKeyWithValue("sql.param", []interface{}{
+				stdsql.Named("id", 1),
+				stdsql.Named("password", "[REDACTED]"),
+			}))
+		})
+	})
+})
+
+var _ = Describe("Reveal", func() {
+	It("returns the original values of the sensitive arguments", func() {
+		value := []string{"secret"}
+
+		args, positions := dialect.Reveal([]interface{}{
+			1,
+			dialect.Sensitive(value),
+			stdsql.Named("password", dialect.Sensitive("secret")),
+		})
+
+		Expect(args).To(Equal([]interface{}{1, value, stdsql.Named("password", "secret")}))
+		Expect(positions).To(Equal([]int{1, 2}))
+	})
+
+	Context("when there are no sensitive arguments", func() {
+		It("returns the arguments", func() {
+			args, positions := dialect.Reveal([]interface{}{1, "root"})
+			Expect(args).To(Equal([]interface{}{1, "root"}))
+			Expect(positions).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,315 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric represents the metric of a single executed statement.
+type Metric struct {
+	// Fingerprint is the normalized statement or the routine name.
+	Fingerprint string
+	// Operation is "query", "exec" or "rows". The rows metric is collected
+	// when the result of a query is closed. It reports the rows read from the
+	// result and the time spent reading them.
+	Operation string
+	// Duration is the time spent executing the statement.
+	Duration time.Duration
+	// Rows is the number of rows returned by a query or affected by an exec.
+	Rows int64
+	// Err is the error returned by the statement, if any.
+	Err error
+}
+
+// MetricsCollector collects the metrics of the executed statements.
+type MetricsCollector interface {
+	// Collect collects the given metric.
+	Collect(ctx context.Context, metric *Metric)
+}
+
+// RowsObserver is implemented by the query results that can report the
+// number of rows read from them.
+type RowsObserver interface {
+	// Observe registers a function that is called with the number of rows
+	// read when the result is closed.
+	Observe(fn func(count int64))
+}
+
+type routineContextKey struct{}
+
+// SetRoutineContext returns a copy of the context that carries the name of
+// the routine being executed.
+func SetRoutineContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, routineContextKey{}, name)
+}
+
+// GetRoutineContext returns the name of the routine carried by the context.
+func GetRoutineContext(ctx context.Context) string {
+	name, _ := ctx.Value(routineContextKey{}).(string)
+	return name
+}
+
+// MetricsDriver is a driver that collects the metrics of all driver
+// operations.
+type MetricsDriver struct {
+	Driver
+	collector MetricsCollector
+}
+
+// Metrics gets a driver and a collector, and returns a new driver that
+// reports the metrics of all outgoing operations to the collector.
+func Metrics(d Driver, collector MetricsCollector) Driver {
+	return &MetricsDriver{d, collector}
+}
+
+// Exec calls the underlying driver Exec method and collects its metrics.
+func (d *MetricsDriver) Exec(ctx context.Context, query string, args, v interface{}) error {
+	return collectExec(ctx, d.collector, d.Dialect(), query, v, func() error {
+		return d.Driver.Exec(ctx, query, args, v)
+	})
+}
+
+// Query calls the underlying driver Query method and collects its metrics.
+func (d *MetricsDriver) Query(ctx context.Context, query string, args, v interface{}) error {
+	return collectQuery(ctx, d.collector, d.Dialect(), query, v, func() error {
+		return d.Driver.Query(ctx, query, args, v)
+	})
+}
+
+// Unwrap returns the underlying driver.
+func (d *MetricsDriver) Unwrap() Driver {
+	return d.Driver
+}
+
+// Validate calls the underlying driver Validate method.
+func (d *MetricsDriver) Validate(ctx context.Context, query string) error {
+	return Validate(ctx, d.Driver, query)
+}
+
+// Tx calls the underlying driver Tx command.
+func (d *MetricsDriver) Tx(ctx context.Context) (Tx, error) {
+	return d.BeginTx(ctx, nil)
+}
+
+// BeginTx calls the underlying driver BeginTx command and returns a
+// transaction that collects the metrics of its operations.
+func (d *MetricsDriver) BeginTx(ctx context.Context, opts *TxOptions) (Tx, error) {
+	tx, err := d.Driver.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricsTx{tx, d.Dialect(), d.collector}, nil
+}
+
+// MetricsTx is a transaction implementation that collects the metrics of all
+// transaction operations.
+type MetricsTx struct {
+	Tx
+	dialect   string
+	collector MetricsCollector
+}
+
+// Exec calls the underlying transaction Exec method and collects its metrics.
+func (d *MetricsTx) Exec(ctx context.Context, query string, args, v interface{}) error {
+	return collectExec(ctx, d.collector, d.dialect, query, v, func() error {
+		return d.Tx.Exec(ctx, query, args, v)
+	})
+}
+
+// Query calls the underlying transaction Query method and collects its metrics.
+func (d *MetricsTx) Query(ctx context.Context, query string, args, v interface{}) error {
+	return collectQuery(ctx, d.collector, d.dialect, query, v, func() error {
+		return d.Tx.Query(ctx, query, args, v)
+	})
+}
+
+func collectExec(ctx context.Context, collector MetricsCollector, dialect, query string, v interface{}, fn func() error) error {
+	var (
+		start = time.Now()
+		err   = fn()
+	)
+
+	metric := &Metric{
+		Fingerprint: fingerprintOf(ctx, dialect, query),
+		Operation:   "exec",
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+
+	if result, ok := v.(*sql.Result); ok && err == nil && *result != nil {
+		metric.Rows, _ = (*result).RowsAffected()
+	}
+
+	collector.Collect(ctx, metric)
+	return err
+}
+
+func collectQuery(ctx context.Context, collector MetricsCollector, dialect, query string, v interface{}, fn func() error) error {
+	var (
+		start = time.Now()
+		err   = fn()
+	)
+
+	metric := &Metric{
+		Fingerprint: fingerprintOf(ctx, dialect, query),
+		Operation:   "query",
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+
+	collector.Collect(ctx, metric)
+
+	// the number of returned rows is known when the rows are closed
+	if observer, ok := v.(RowsObserver); ok && err == nil {
+		start = time.Now()
+
+		observer.Observe(func(count int64) {
+			collector.Collect(ctx, &Metric{
+				Fingerprint: metric.Fingerprint,
+				Operation:   "rows",
+				Duration:    time.Since(start),
+				Rows:        count,
+			})
+		})
+	}
+
+	return err
+}
+
+func fingerprintOf(ctx context.Context, dialect, query string) string {
+	if name := GetRoutineContext(ctx); name != "" {
+		return name
+	}
+
+	return Fingerprint(query, dialect)
+}
+
+var fingerprintList = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+
+// Fingerprint normalizes the given SQL statement. It replaces the literals
+// and the placeholders with '?', collapses the IN lists, removes the
+// comments and collapses the whitespaces. The statements that differ only in
+// their arguments have the same fingerprint. The query is tokenized in the
+// given dialect.
+//
+//	SELECT * FROM users WHERE id IN ($1, $2) AND name = 'root'
+//
+// becomes
+//
+//	SELECT * FROM users WHERE id IN (...) AND name = ?
+func Fingerprint(query, dialect string) string {
+	var (
+		buffer    = &strings.Builder{}
+		space     = false
+		tokens, _ = Tokenize(query, dialect)
+	)
+
+	write := func(value string) {
+		if space && buffer.Len() > 0 {
+			buffer.WriteByte(' ')
+		}
+
+		space = false
+		buffer.WriteString(value)
+	}
+
+	for _, token := range tokens {
+		switch token.Kind {
+		case TokenSpace, TokenComment:
+			space = true
+		case TokenString, TokenNumber, TokenNamed, TokenPositional, TokenParam:
+			write("?")
+		default:
+			write(token.Raw)
+		}
+	}
+
+	return fingerprintList.ReplaceAllString(buffer.String(), "IN (...)")
+}
+
+// MetricStats represents the aggregated metrics of a statement.
+type MetricStats struct {
+	// Count is the number of executions.
+	Count int64
+	// Errors is the number of failed executions.
+	Errors int64
+	// Rows is the total number of rows returned or affected.
+	Rows int64
+	// Durations are the durations of all executions. The time spent reading
+	// the rows is not included.
+	Durations []time.Duration
+}
+
+// MemoryCollector is a MetricsCollector that aggregates the metrics in the
+// memory. It's usually used in tests.
+type MemoryCollector struct {
+	mu    sync.Mutex
+	stats map[string]*MetricStats
+}
+
+// Collect collects the given metric.
+func (c *MemoryCollector) Collect(ctx context.Context, metric *Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats == nil {
+		c.stats = make(map[string]*MetricStats)
+	}
+
+	stats, ok := c.stats[metric.Fingerprint]
+	if !ok {
+		stats = &MetricStats{}
+		c.stats[metric.Fingerprint] = stats
+	}
+
+	stats.Rows += metric.Rows
+
+	// the rows are read after the execution of the query
+	if metric.Operation == "rows" {
+		return
+	}
+
+	stats.Count++
+	stats.Durations = append(stats.Durations, metric.Duration)
+
+	if metric.Err != nil {
+		stats.Errors++
+	}
+}
+
+// Fingerprints returns the sorted fingerprints of all collected statements.
+func (c *MemoryCollector) Fingerprints() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fingerprints := make([]string, 0, len(c.stats))
+
+	for fingerprint := range c.stats {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+
+	sort.Strings(fingerprints)
+	return fingerprints
+}
+
+// Stats returns a copy of the aggregated metrics of the statement with the
+// given fingerprint. It returns nil if there are no metrics.
+func (c *MemoryCollector) Stats(fingerprint string) *MetricStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats, ok := c.stats[fingerprint]
+	if !ok {
+		return nil
+	}
+
+	clone := *stats
+	clone.Durations = append([]time.Duration{}, stats.Durations...)
+	return &clone
+}
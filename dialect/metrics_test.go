@@ -0,0 +1,126 @@
+package dialect_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fingerprint", func() {
+	DescribeTable("normalizes the query",
+		func(query, fingerprint string) {
+			Expect(dialect.Fingerprint(query, "")).To(Equal(fingerprint))
+		},
+		Entry("string literals", "SELECT * FROM users WHERE name = 'jack''s'", "SELECT * FROM users WHERE name = ?"),
+		Entry("numeric literals", "SELECT * FROM users WHERE id = 10 AND score > 1.5", "SELECT * FROM users WHERE id = ? AND score > ?"),
+		Entry("identifiers with digits", "SELECT t1.id FROM users2 AS t1", "SELECT t1.id FROM users2 AS t1"),
+		Entry("quoted identifiers", "SELECT \"a 1\", `b 2` FROM users", "SELECT \"a 1\", `b 2` FROM users"),
+		Entry("placeholders", "SELECT * FROM users WHERE a = $1 AND b = :name AND c = @p1 AND d = ?", "SELECT * FROM users WHERE a = ? AND b = ? AND c = ? AND d = ?"),
+		Entry("casts", "SELECT created_at::date FROM users", "SELECT created_at::date FROM users"),
+		Entry("in lists", "SELECT * FROM users WHERE id IN (1, 2, 3) OR id in ($1,$2)", "SELECT * FROM users WHERE id IN (...) OR id IN (...)"),
+		Entry("comments", "SELECT * -- all columns\nFROM /* the table */ users", "SELECT * FROM users"),
+		Entry("whitespaces", "  SELECT *\n\tFROM   users  ", "SELECT * FROM users"),
+		Entry("quotes in comments", "SELECT * /* it's */ FROM users -- don't\nWHERE id = 1", "SELECT * FROM users WHERE id = ?"),
+		Entry("dollar-quoted strings", "SELECT $body$ it's 'quoted' $body$, price$ FROM users", "SELECT ?, price$ FROM users"),
+	)
+
+	It("tokenizes the query in the given dialect", func() {
+		query := `SELECT * FROM users WHERE name = 'it\'s' AND id = 1`
+		Expect(dialect.Fingerprint(query, dialect.MySQL)).To(Equal("SELECT * FROM users WHERE name = ? AND id = ?"))
+		Expect(dialect.Fingerprint("SELECT /* a /* b */ 'c' */ 1", dialect.Postgres)).To(Equal("SELECT ?"))
+	})
+})
+
+var _ = Describe("MetricsDriver", func() {
+	var (
+		ctx       context.Context
+		collector *dialect.MemoryCollector
+		driver    dialect.Driver
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		collector = &dialect.MemoryCollector{}
+
+		db, err := sql.Open("sqlite3", "file:metrics.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		driver = dialect.Metrics(db, collector)
+		Expect(driver.Exec(ctx, "CREATE TABLE users (id int)", []interface{}{}, nil)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(driver.Exec(ctx, "DROP TABLE users", []interface{}{}, nil)).To(Succeed())
+		Expect(driver.Close()).To(Succeed())
+	})
+
+	It("collects the rows affected", func() {
+		var result sql.Result
+
+		for i := 0; i < 3; i++ {
+			query := fmt.Sprintf("INSERT INTO users VALUES (%d), (%d)", i, i+10)
+			Expect(driver.Exec(ctx, query, []interface{}{}, &result)).To(Succeed())
+		}
+
+		stats := collector.Stats("INSERT INTO users VALUES (?), (?)")
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Count).To(BeEquivalentTo(3))
+		Expect(stats.Rows).To(BeEquivalentTo(6))
+		Expect(stats.Errors).To(BeZero())
+		Expect(stats.Durations).To(HaveLen(3))
+	})
+
+	It("collects the rows returned", func() {
+		Expect(driver.Exec(ctx, "INSERT INTO users VALUES (1), (2)", []interface{}{}, nil)).To(Succeed())
+
+		rows := &sql.Rows{}
+		Expect(driver.Query(ctx, "SELECT * FROM users WHERE id IN (?, ?)", []interface{}{1, 2}, rows)).To(Succeed())
+
+		stats := collector.Stats("SELECT * FROM users WHERE id IN (...)")
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Count).To(BeEquivalentTo(1))
+		Expect(stats.Rows).To(BeZero())
+		Expect(stats.Durations).To(HaveLen(1))
+
+		for rows.Next() {
+		}
+
+		Expect(rows.Close()).To(Succeed())
+
+		stats = collector.Stats("SELECT * FROM users WHERE id IN (...)")
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Count).To(BeEquivalentTo(1))
+		Expect(stats.Rows).To(BeEquivalentTo(2))
+		Expect(stats.Durations).To(HaveLen(1))
+	})
+
+	It("collects the errors", func() {
+		rows := &sql.Rows{}
+		Expect(driver.Query(ctx, "SELECT * FROM unknown", []interface{}{}, rows)).To(MatchError("no such table: unknown"))
+
+		stats := collector.Stats("SELECT * FROM unknown")
+		Expect(stats).NotTo(BeNil())
+		Expect(stats.Errors).To(BeEquivalentTo(1))
+	})
+
+	It("uses the routine name as fingerprint", func() {
+		ctx := dialect.SetRoutineContext(ctx, "insert-user")
+		Expect(driver.Exec(ctx, "INSERT INTO users VALUES (1)", []interface{}{}, nil)).To(Succeed())
+		Expect(collector.Fingerprints()).To(ContainElement("insert-user"))
+	})
+
+	It("collects the metrics within the transaction", func() {
+		tx, err := driver.Tx(ctx)
+		Expect(err).To(Succeed())
+		Expect(tx.Exec(ctx, "DELETE FROM users", []interface{}{}, nil)).To(Succeed())
+		Expect(tx.Commit()).To(Succeed())
+
+		Expect(collector.Fingerprints()).To(ContainElement("DELETE FROM users"))
+	})
+})
@@ -0,0 +1,51 @@
+package dialect
+
+import (
+	"fmt"
+	"time"
+)
+
+// Migration represents a migration of the database schema.
+type Migration struct {
+	// ID is the version of the migration.
+	ID string
+	// Description is the short description of the migration.
+	Description string
+	// AppliedAt is the time when the migration was applied. It's zero if the
+	// migration is pending.
+	AppliedAt time.Time
+	// Modified reports whether the migration files were changed after the
+	// migration was applied.
+	Modified bool
+	// Statements are the statements executed by the migration operation. In
+	// dry-run mode they are the statements that would be executed.
+	Statements []string
+}
+
+// Pending reports whether the migration is not applied yet.
+func (m *Migration) Pending() bool {
+	return m.AppliedAt.IsZero()
+}
+
+// String returns the migration as string
+func (m *Migration) String() string {
+	return fmt.Sprintf("%s_%s", m.ID, m.Description)
+}
+
+// MigrateOptions holds the options to be used in Driver.MigrateTo and
+// Driver.Rollback.
+type MigrateOptions struct {
+	// DryRun returns the migrations with the statements that would be
+	// executed without changing the database.
+	DryRun bool
+	// Repair updates the recorded checksums of the applied migration files
+	// that were changed instead of failing with an error.
+	Repair bool
+	// LockTimeout is the maximum time to wait for the migration lock. If
+	// zero, it's one minute.
+	LockTimeout time.Duration
+	// LockExpiration is the age after which the SQLite lock row is taken
+	// over, because the process that holds it has crashed. If zero, it's
+	// ten minutes.
+	LockExpiration time.Duration
+}
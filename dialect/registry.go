@@ -0,0 +1,207 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dialect describes the syntax and the capabilities of a database. The
+// builders, the named queries and the schema tools consult the dialect
+// registered for the driver name instead of comparing the names.
+//
+// A dialect may also implement orm.ErrorTranslator to classify the errors of
+// its driver. Otherwise, the translator of its family is used.
+type Dialect interface {
+	// Name returns the name of the dialect.
+	Name() string
+	// Family returns the name of the built-in dialect whose syntax is
+	// followed by the dialect. For example, CockroachDB follows PostgreSQL
+	// and MariaDB follows MySQL.
+	Family() string
+	// Quote quotes the given identifier.
+	Quote(ident string) string
+	// Placeholder returns the style of the query parameters.
+	Placeholder() Placeholder
+	// Supports reports whether the dialect supports the given feature.
+	Supports(feature Feature) bool
+	// Upsert returns the syntax of the INSERT statements that update the
+	// conflicting rows.
+	Upsert() Upsert
+	// Limit returns the clause that limits the rows of a SELECT statement.
+	// Any of the arguments may be nil.
+	Limit(limit, offset *int) string
+	// ColumnType returns the column type of the given type and size. The
+	// size is zero if it's not defined.
+	ColumnType(typ Type, size int) (string, bool)
+}
+
+// Placeholder is the style of the query parameters.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion references the parameters with a question mark.
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar references the parameters by their position: $1, $2.
+	PlaceholderDollar
+	// PlaceholderAt references the parameters by their name: @name. The
+	// positional parameters are named @p1, @p2.
+	PlaceholderAt
+)
+
+// Format returns the placeholder of the parameter at the given position,
+// starting from 1.
+func (p Placeholder) Format(index int) string {
+	switch p {
+	case PlaceholderDollar:
+		return fmt.Sprintf("$%d", index)
+	case PlaceholderAt:
+		return fmt.Sprintf("@p%d", index)
+	default:
+		return "?"
+	}
+}
+
+// Feature is an optional capability of a dialect.
+type Feature int
+
+const (
+	// FeatureReturning is the RETURNING clause of the INSERT, UPDATE and
+	// DELETE statements.
+	FeatureReturning Feature = iota + 1
+	// FeatureLock is the FOR UPDATE/SHARE clause of the SELECT statements.
+	FeatureLock
+	// FeatureSchema is the schema qualifier of the table names.
+	FeatureSchema
+)
+
+// Upsert is the syntax of the INSERT statements that update the conflicting
+// rows.
+type Upsert int
+
+const (
+	// UpsertNone is used by the dialects that cannot update the conflicting
+	// rows.
+	UpsertNone Upsert = iota
+	// UpsertOnConflict is the ON CONFLICT clause.
+	UpsertOnConflict
+	// UpsertOnDuplicateKey is the ON DUPLICATE KEY UPDATE clause.
+	UpsertOnDuplicateKey
+	// UpsertMerge is the MERGE statement.
+	UpsertMerge
+)
+
+// Type is a portable column type that is mapped to the column types of the
+// dialects.
+type Type int
+
+// The portable column types.
+const (
+	TypeBool Type = iota + 1
+	TypeInt8
+	TypeInt16
+	TypeInt32
+	TypeInt64
+	TypeUint8
+	TypeUint16
+	TypeUint32
+	TypeUint64
+	TypeFloat32
+	TypeFloat64
+	TypeString
+	TypeBytes
+	TypeTime
+	TypeJSON
+)
+
+var registry = struct {
+	sync.RWMutex
+	items map[string]Dialect
+}{
+	items: map[string]Dialect{},
+}
+
+func init() {
+	Register(SQLite, &sqlite{})
+	Register("sqlite", &sqlite{})
+	Register(MySQL, &mysql{})
+	Register(Postgres, &postgres{})
+	Register("pgx", &postgres{})
+	Register(SQLServer, &sqlserver{})
+}
+
+// Register registers the dialect for the given driver name. It replaces the
+// dialect registered for the same name. The built-in dialects can be
+// extended by embedding them:
+//
+//	type CockroachDB struct {
+//		dialect.Dialect
+//	}
+//
+//	func (CockroachDB) Name() string {
+//		return "cockroach"
+//	}
+//
+//	dialect.Register("cockroach", &CockroachDB{dialect.MustLookup(dialect.Postgres)})
+func Register(name string, dialect Dialect) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.items[name] = dialect
+}
+
+// Lookup returns the dialect registered for the given driver name. The name
+// may be the name of a wrapped driver (e.g. sqlite3-instrumented). In that
+// case, the dialect with the longest matching prefix is returned.
+func Lookup(name string) (Dialect, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if dialect, ok := registry.items[name]; ok {
+		return dialect, true
+	}
+
+	var (
+		prefix  string
+		dialect Dialect
+	)
+
+	for key, item := range registry.items {
+		if strings.HasPrefix(name, key) && len(key) > len(prefix) {
+			prefix = key
+			dialect = item
+		}
+	}
+
+	return dialect, dialect != nil
+}
+
+// Registered reports whether a dialect is registered for exactly the given
+// driver name. Unlike Lookup, it does not match the prefixes of the name.
+func Registered(name string) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	_, ok := registry.items[name]
+	return ok
+}
+
+// MustLookup is like Lookup, but panics if the dialect is not registered.
+func MustLookup(name string) Dialect {
+	dialect, ok := Lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("dialect: %q is not registered", name))
+	}
+
+	return dialect
+}
+
+// FamilyOf returns the family of the dialect registered for the given driver
+// name. It returns the name as it is if the dialect is not registered.
+func FamilyOf(name string) string {
+	if dialect, ok := Lookup(name); ok {
+		return dialect.Family()
+	}
+
+	return name
+}
@@ -0,0 +1,114 @@
+package dialect_test
+
+import (
+	"github.com/phogolabs/orm/dialect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type MariaDB struct {
+	dialect.Dialect
+}
+
+func (MariaDB) Name() string {
+	return "mariadb"
+}
+
+func (MariaDB) Supports(feature dialect.Feature) bool {
+	return feature != dialect.FeatureSchema
+}
+
+var _ = Describe("Registry", func() {
+	DescribeTable("looks up the built-in dialects",
+		func(name, dialectName string, placeholder dialect.Placeholder) {
+			spec, ok := dialect.Lookup(name)
+			Expect(ok).To(BeTrue())
+			Expect(spec.Name()).To(Equal(dialectName))
+			Expect(spec.Family()).To(Equal(dialectName))
+			Expect(spec.Placeholder()).To(Equal(placeholder))
+		},
+		Entry("sqlite3", "sqlite3", dialect.SQLite, dialect.PlaceholderQuestion),
+		Entry("sqlite", "sqlite", dialect.SQLite, dialect.PlaceholderQuestion),
+		Entry("mysql", "mysql", dialect.MySQL, dialect.PlaceholderQuestion),
+		Entry("postgres", "postgres", dialect.Postgres, dialect.PlaceholderDollar),
+		Entry("pgx", "pgx", dialect.Postgres, dialect.PlaceholderDollar),
+		Entry("sqlserver", "sqlserver", dialect.SQLServer, dialect.PlaceholderAt),
+		Entry("wrapped driver", "sqlite3-instrumented", dialect.SQLite, dialect.PlaceholderQuestion),
+	)
+
+	It("registers a third-party dialect", func() {
+		dialect.Register("mariadb", &MariaDB{dialect.MustLookup(dialect.MySQL)})
+
+		spec, ok := dialect.Lookup("mariadb")
+		Expect(ok).To(BeTrue())
+		Expect(spec.Name()).To(Equal("mariadb"))
+		Expect(spec.Family()).To(Equal(dialect.MySQL))
+		Expect(spec.Supports(dialect.FeatureSchema)).To(BeFalse())
+		Expect(spec.Upsert()).To(Equal(dialect.UpsertOnDuplicateKey))
+		Expect(dialect.FamilyOf("mariadb")).To(Equal(dialect.MySQL))
+	})
+
+	It("reports the exactly registered names", func() {
+		Expect(dialect.Registered("pgx")).To(BeTrue())
+		Expect(dialect.Registered("sqlite3-instrumented")).To(BeFalse())
+		Expect(dialect.Registered("mysqlbackup")).To(BeFalse())
+	})
+
+	Context("when the dialect is not registered", func() {
+		It("does not find it", func() {
+			_, ok := dialect.Lookup("oci8")
+			Expect(ok).To(BeFalse())
+			Expect(dialect.FamilyOf("oci8")).To(Equal("oci8"))
+			Expect(func() { dialect.MustLookup("oci8") }).To(PanicWith(`dialect: "oci8" is not registered`))
+		})
+	})
+})
+
+var _ = Describe("Dialect", func() {
+	It("quotes the identifiers", func() {
+		Expect(dialect.MustLookup(dialect.MySQL).Quote("users")).To(Equal("`users`"))
+		Expect(dialect.MustLookup(dialect.Postgres).Quote("users")).To(Equal(`"users"`))
+		Expect(dialect.MustLookup(dialect.Postgres).Quote("`users`")).To(Equal(`"users"`))
+		Expect(dialect.MustLookup(dialect.SQLServer).Quote("users")).To(Equal("[users]"))
+		Expect(dialect.MustLookup(dialect.SQLServer).Quote("`users`.`id`")).To(Equal("[users].[id]"))
+	})
+
+	It("formats the placeholders", func() {
+		Expect(dialect.PlaceholderQuestion.Format(2)).To(Equal("?"))
+		Expect(dialect.PlaceholderDollar.Format(2)).To(Equal("$2"))
+		Expect(dialect.PlaceholderAt.Format(2)).To(Equal("@p2"))
+	})
+
+	It("renders the LIMIT and OFFSET clauses", func() {
+		limit, offset := 10, 20
+
+		spec := dialect.MustLookup(dialect.Postgres)
+		Expect(spec.Limit(&limit, &offset)).To(Equal("LIMIT 10 OFFSET 20"))
+		Expect(spec.Limit(nil, &offset)).To(Equal("OFFSET 20"))
+
+		spec = dialect.MustLookup(dialect.SQLServer)
+		Expect(spec.Limit(&limit, &offset)).To(Equal("OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"))
+		Expect(spec.Limit(&limit, nil)).To(Equal("OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"))
+	})
+
+	It("reports the capabilities", func() {
+		Expect(dialect.MustLookup(dialect.SQLite).Supports(dialect.FeatureReturning)).To(BeTrue())
+		Expect(dialect.MustLookup(dialect.SQLite).Supports(dialect.FeatureLock)).To(BeFalse())
+		Expect(dialect.MustLookup(dialect.MySQL).Supports(dialect.FeatureReturning)).To(BeFalse())
+		Expect(dialect.MustLookup(dialect.Postgres).Upsert()).To(Equal(dialect.UpsertOnConflict))
+	})
+
+	DescribeTable("maps the column types",
+		func(name string, typ dialect.Type, size int, column string) {
+			value, ok := dialect.MustLookup(name).ColumnType(typ, size)
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal(column))
+		},
+		Entry("sqlite string", dialect.SQLite, dialect.TypeString, 0, "text"),
+		Entry("mysql sized string", dialect.MySQL, dialect.TypeString, 64, "varchar(64)"),
+		Entry("mysql unsigned", dialect.MySQL, dialect.TypeUint32, 0, "int unsigned"),
+		Entry("postgres unsigned", dialect.Postgres, dialect.TypeUint64, 0, "numeric(20)"),
+		Entry("postgres time", dialect.Postgres, dialect.TypeTime, 0, "timestamp with time zone"),
+	)
+})
@@ -0,0 +1,98 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// redacted is the value logged instead of a sensitive value
+const redacted = "[REDACTED]"
+
+// SensitiveValue is an argument whose value is masked in the logs. It's
+// replaced with the original value before the query is sent to the database.
+type SensitiveValue struct {
+	value interface{}
+}
+
+// Sensitive marks the given argument as sensitive.
+func Sensitive(value interface{}) SensitiveValue {
+	return SensitiveValue{value: value}
+}
+
+// Value implements the driver.Valuer interface.
+func (v SensitiveValue) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(v.value)
+}
+
+// String implements the fmt.Stringer interface.
+func (v SensitiveValue) String() string {
+	return redacted
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+type sensitiveContextKey struct{}
+
+// SetSensitiveContext returns a copy of the context that carries the
+// positions of the sensitive arguments of the query being executed.
+func SetSensitiveContext(ctx context.Context, positions []int) context.Context {
+	return context.WithValue(ctx, sensitiveContextKey{}, positions)
+}
+
+// GetSensitiveContext returns the positions of the sensitive arguments
+// carried by the context.
+func GetSensitiveContext(ctx context.Context) []int {
+	positions, _ := ctx.Value(sensitiveContextKey{}).([]int)
+	return positions
+}
+
+// Reveal returns a copy of the arguments where the sensitive values are
+// replaced with the original ones and the positions of the sensitive values.
+// It returns the arguments as they are if none of them is sensitive.
+func Reveal(args []interface{}) ([]interface{}, []int) {
+	var (
+		values    []interface{}
+		positions []int
+	)
+
+	for index, arg := range args {
+		value, ok := reveal(arg)
+		if !ok {
+			continue
+		}
+
+		if values == nil {
+			values = make([]interface{}, len(args))
+			copy(values, args)
+		}
+
+		values[index] = value
+		positions = append(positions, index)
+	}
+
+	if values == nil {
+		return args, nil
+	}
+
+	return values, positions
+}
+
+// reveal returns the original value of the sensitive argument.
+func reveal(arg interface{}) (interface{}, bool) {
+	switch value := arg.(type) {
+	case SensitiveValue:
+		return value.value, true
+	case sql.NamedArg:
+		if sensitive, ok := value.Value.(SensitiveValue); ok {
+			value.Value = sensitive.value
+			return value, true
+		}
+	}
+
+	return arg, false
+}
@@ -598,7 +598,7 @@ func (i *IndexBuilder) Query() (string, []interface{}) {
 	i.Ident(i.name)
 	i.WriteString(" ON ")
 	i.Ident(i.table)
-	switch i.dialect {
+	switch i.family() {
 	case dialect.Postgres:
 		if i.method != "" {
 			i.WriteString(" USING ").Ident(i.method)
@@ -663,6 +663,465 @@ func (d *DropIndexBuilder) Query() (string, []interface{}) {
 	return d.String(), nil
 }
 
+// SavepointBuilder is a builder for the `SAVEPOINT`, `ROLLBACK TO SAVEPOINT`
+// and `RELEASE SAVEPOINT` statements.
+type SavepointBuilder struct {
+	Builder
+	name   string
+	action string
+}
+
+// Savepoint creates a builder for the `SAVEPOINT` statement.
+//
+//	Savepoint("sp1")
+//
+func Savepoint(name string) *SavepointBuilder {
+	return &SavepointBuilder{name: name, action: "SAVEPOINT"}
+}
+
+// RollbackToSavepoint creates a builder for the `ROLLBACK TO SAVEPOINT` statement.
+//
+//	RollbackToSavepoint("sp1")
+//
+func RollbackToSavepoint(name string) *SavepointBuilder {
+	return &SavepointBuilder{name: name, action: "ROLLBACK TO SAVEPOINT"}
+}
+
+// ReleaseSavepoint creates a builder for the `RELEASE SAVEPOINT` statement.
+//
+//	ReleaseSavepoint("sp1")
+//
+func ReleaseSavepoint(name string) *SavepointBuilder {
+	return &SavepointBuilder{name: name, action: "RELEASE SAVEPOINT"}
+}
+
+// Query returns query representation of a savepoint statement.
+//
+//	[ROLLBACK TO | RELEASE] SAVEPOINT name
+//
+func (s *SavepointBuilder) Query() (string, []interface{}) {
+	s.WriteString(s.action).WriteChar(' ')
+	s.Ident(s.name)
+	return s.String(), nil
+}
+
+// DropTableBuilder is a builder for `DROP TABLE` statement.
+type DropTableBuilder struct {
+	Builder
+	names   []string
+	exists  bool
+	cascade bool
+}
+
+// DropTable creates a builder for the `DROP TABLE` statement.
+//
+//	DropTable("users", "groups").
+//		IfExists().
+//		Cascade()
+//
+func DropTable(names ...string) *DropTableBuilder {
+	return &DropTableBuilder{names: names}
+}
+
+// IfExists appends the `IF EXISTS` clause to the `DROP TABLE` statement.
+func (d *DropTableBuilder) IfExists() *DropTableBuilder {
+	d.exists = true
+	return d
+}
+
+// Cascade appends the `CASCADE` option to the `DROP TABLE` statement. The
+// option is omitted in SQLite that does not support it.
+func (d *DropTableBuilder) Cascade() *DropTableBuilder {
+	d.cascade = true
+	return d
+}
+
+// Query returns query representation of a `DROP TABLE` statement.
+//
+//	DROP TABLE [IF EXISTS] name [, ...] [CASCADE]
+//
+func (d *DropTableBuilder) Query() (string, []interface{}) {
+	d.WriteString("DROP TABLE ")
+	if d.exists {
+		d.WriteString("IF EXISTS ")
+	}
+	d.IdentComma(d.names...)
+	if d.cascade && !d.sqlite() {
+		d.WriteString(" CASCADE")
+	}
+	return d.String(), nil
+}
+
+// TruncateBuilder is a builder for `TRUNCATE TABLE` statement.
+type TruncateBuilder struct {
+	Builder
+	name    string
+	restart bool
+	cascade bool
+}
+
+// Truncate creates a builder for the `TRUNCATE TABLE` statement. SQLite does
+// not support it and the statement is emulated with `DELETE FROM`.
+//
+//	Truncate("users")
+//
+func Truncate(name string) *TruncateBuilder {
+	return &TruncateBuilder{name: name}
+}
+
+// RestartIdentity appends the `RESTART IDENTITY` option to the statement.
+// PostgreSQL only.
+func (t *TruncateBuilder) RestartIdentity() *TruncateBuilder {
+	t.restart = true
+	return t
+}
+
+// Cascade appends the `CASCADE` option to the statement. PostgreSQL only.
+func (t *TruncateBuilder) Cascade() *TruncateBuilder {
+	t.cascade = true
+	return t
+}
+
+// Query returns query representation of a `TRUNCATE TABLE` statement.
+//
+//	TRUNCATE TABLE name [RESTART IDENTITY] [CASCADE]
+//
+func (t *TruncateBuilder) Query() (string, []interface{}) {
+	if t.sqlite() {
+		t.WriteString("DELETE FROM ")
+		t.Ident(t.name)
+		return t.String(), nil
+	}
+	t.WriteString("TRUNCATE TABLE ")
+	t.Ident(t.name)
+	if t.postgres() {
+		if t.restart {
+			t.WriteString(" RESTART IDENTITY")
+		}
+		if t.cascade {
+			t.WriteString(" CASCADE")
+		}
+	}
+	return t.String(), nil
+}
+
+// ViewBuilder is a builder for `CREATE VIEW` statement.
+type ViewBuilder struct {
+	Builder
+	name    string
+	replace bool
+	exists  bool
+	columns []string
+	as      Querier
+}
+
+// CreateView creates a builder for the `CREATE VIEW` statement.
+//
+//	CreateView("active_users").
+//		OrReplace().
+//		As(Select().From(Table("users")).Where(EQ("active", true)))
+//
+func CreateView(name string) *ViewBuilder {
+	return &ViewBuilder{name: name}
+}
+
+// OrReplace appends the `OR REPLACE` clause to the `CREATE VIEW` statement.
+// SQLite does not support it.
+func (v *ViewBuilder) OrReplace() *ViewBuilder {
+	v.replace = true
+	return v
+}
+
+// IfNotExists appends the `IF NOT EXISTS` clause to the `CREATE VIEW`
+// statement. SQLite only.
+func (v *ViewBuilder) IfNotExists() *ViewBuilder {
+	v.exists = true
+	return v
+}
+
+// Columns sets the column names of the view.
+func (v *ViewBuilder) Columns(columns ...string) *ViewBuilder {
+	v.columns = append(v.columns, columns...)
+	return v
+}
+
+// As sets the query of the view.
+func (v *ViewBuilder) As(query Querier) *ViewBuilder {
+	v.as = query
+	return v
+}
+
+// Query returns query representation of a `CREATE VIEW` statement.
+//
+//	CREATE [OR REPLACE] VIEW [IF NOT EXISTS] name [(columns)] AS query
+//
+func (v *ViewBuilder) Query() (string, []interface{}) {
+	v.WriteString("CREATE ")
+	if v.replace {
+		if v.sqlite() {
+			v.AddError(errors.New("sql: CREATE OR REPLACE VIEW not supported in SQLite"))
+		}
+		v.WriteString("OR REPLACE ")
+	}
+	v.WriteString("VIEW ")
+	if v.exists {
+		if !v.sqlite() {
+			v.AddError(errors.New("sql: CREATE VIEW IF NOT EXISTS supported only in SQLite"))
+		}
+		v.WriteString("IF NOT EXISTS ")
+	}
+	v.Ident(v.name)
+	if len(v.columns) > 0 {
+		v.Nested(func(b *Builder) {
+			b.IdentComma(v.columns...)
+		})
+	}
+	if v.as == nil {
+		v.AddError(fmt.Errorf("sql: missing query for view %q", v.name))
+		return v.String(), v.args
+	}
+	v.WriteString(" AS ")
+	v.Join(v.as)
+	return v.String(), v.args
+}
+
+// DropViewBuilder is a builder for `DROP VIEW` statement.
+type DropViewBuilder struct {
+	Builder
+	names   []string
+	exists  bool
+	cascade bool
+}
+
+// DropView creates a builder for the `DROP VIEW` statement.
+//
+//	DropView("active_users").
+//		IfExists()
+//
+func DropView(names ...string) *DropViewBuilder {
+	return &DropViewBuilder{names: names}
+}
+
+// IfExists appends the `IF EXISTS` clause to the `DROP VIEW` statement.
+func (d *DropViewBuilder) IfExists() *DropViewBuilder {
+	d.exists = true
+	return d
+}
+
+// Cascade appends the `CASCADE` option to the `DROP VIEW` statement. The
+// option is omitted in SQLite that does not support it.
+func (d *DropViewBuilder) Cascade() *DropViewBuilder {
+	d.cascade = true
+	return d
+}
+
+// Query returns query representation of a `DROP VIEW` statement.
+//
+//	DROP VIEW [IF EXISTS] name [, ...] [CASCADE]
+//
+func (d *DropViewBuilder) Query() (string, []interface{}) {
+	d.WriteString("DROP VIEW ")
+	if d.exists {
+		d.WriteString("IF EXISTS ")
+	}
+	d.IdentComma(d.names...)
+	if d.cascade && !d.sqlite() {
+		d.WriteString(" CASCADE")
+	}
+	return d.String(), nil
+}
+
+// TriggerBuilder is a builder for `CREATE TRIGGER` statement.
+type TriggerBuilder struct {
+	Builder
+	name     string
+	timing   string
+	events   []string
+	table    string
+	row      bool
+	when     Querier
+	function string
+	body     []Querier
+}
+
+// CreateTrigger creates a builder for the `CREATE TRIGGER` statement. The
+// PostgreSQL triggers execute a function, while the MySQL and SQLite triggers
+// execute the statements of their body.
+//
+//	PostgreSQL:
+//
+//		CreateTrigger("users_updated_at").
+//			Before("UPDATE").
+//			On("users").
+//			ForEachRow().
+//			Execute("set_updated_at")
+//
+//	MySQL/SQLite:
+//
+//		CreateTrigger("users_updated_at").
+//			After("UPDATE").
+//			On("users").
+//			ForEachRow().
+//			Do(Update("users").Set("updated_at", Expr("CURRENT_TIMESTAMP")).Where(ExprP("id = NEW.id")))
+//
+func CreateTrigger(name string) *TriggerBuilder {
+	return &TriggerBuilder{name: name}
+}
+
+// Before fires the trigger before the given events (INSERT, UPDATE or DELETE).
+func (t *TriggerBuilder) Before(events ...string) *TriggerBuilder {
+	t.timing, t.events = "BEFORE", events
+	return t
+}
+
+// After fires the trigger after the given events (INSERT, UPDATE or DELETE).
+func (t *TriggerBuilder) After(events ...string) *TriggerBuilder {
+	t.timing, t.events = "AFTER", events
+	return t
+}
+
+// InsteadOf fires the trigger instead of the given events on a view. MySQL
+// does not support it.
+func (t *TriggerBuilder) InsteadOf(events ...string) *TriggerBuilder {
+	t.timing, t.events = "INSTEAD OF", events
+	return t
+}
+
+// On sets the table (or the view) of the trigger.
+func (t *TriggerBuilder) On(table string) *TriggerBuilder {
+	t.table = table
+	return t
+}
+
+// ForEachRow fires the trigger once for each affected row.
+func (t *TriggerBuilder) ForEachRow() *TriggerBuilder {
+	t.row = true
+	return t
+}
+
+// When sets the condition of the trigger. MySQL does not support it.
+func (t *TriggerBuilder) When(cond Querier) *TriggerBuilder {
+	t.when = cond
+	return t
+}
+
+// Execute sets the function executed by the trigger. PostgreSQL only.
+func (t *TriggerBuilder) Execute(function string) *TriggerBuilder {
+	t.function = function
+	return t
+}
+
+// Do appends the statements executed by the trigger. MySQL and SQLite only.
+func (t *TriggerBuilder) Do(queries ...Querier) *TriggerBuilder {
+	t.body = append(t.body, queries...)
+	return t
+}
+
+// Query returns query representation of a `CREATE TRIGGER` statement.
+//
+//	CREATE TRIGGER name {BEFORE | AFTER | INSTEAD OF} event [OR ...]
+//		ON table [FOR EACH ROW] [WHEN (condition)]
+//		{EXECUTE FUNCTION function() | statement | BEGIN statements END}
+//
+func (t *TriggerBuilder) Query() (string, []interface{}) {
+	switch {
+	case t.timing == "" || len(t.events) == 0:
+		t.AddError(fmt.Errorf("sql: missing event for trigger %q", t.name))
+	case len(t.events) > 1 && !t.postgres():
+		t.AddError(fmt.Errorf("sql: multiple events for trigger %q supported only in PostgreSQL", t.name))
+	case t.mysql() && t.timing == "INSTEAD OF":
+		t.AddError(errors.New("sql: INSTEAD OF triggers not supported in MySQL"))
+	case t.mysql() && t.when != nil:
+		t.AddError(errors.New("sql: WHEN clause of triggers not supported in MySQL"))
+	case t.postgres() && t.function == "":
+		t.AddError(fmt.Errorf("sql: missing function for trigger %q", t.name))
+	case !t.postgres() && len(t.body) == 0:
+		t.AddError(fmt.Errorf("sql: missing statements for trigger %q", t.name))
+	}
+	t.WriteString("CREATE TRIGGER ")
+	t.Ident(t.name)
+	t.WriteString(" " + t.timing + " " + strings.Join(t.events, " OR "))
+	t.WriteString(" ON ")
+	t.Ident(t.table)
+	if t.row {
+		t.WriteString(" FOR EACH ROW")
+	}
+	if t.when != nil {
+		t.WriteString(" WHEN ")
+		t.Nested(func(b *Builder) {
+			b.Join(t.when)
+		})
+	}
+	switch {
+	case t.postgres():
+		t.WriteString(" EXECUTE FUNCTION " + t.function + "()")
+	case t.mysql() && len(t.body) == 1:
+		t.Pad().Join(t.body[0])
+	default:
+		t.WriteString(" BEGIN ")
+		for _, q := range t.body {
+			t.Join(q).WriteString("; ")
+		}
+		t.WriteString("END")
+	}
+	return t.String(), t.args
+}
+
+// DropTriggerBuilder is a builder for `DROP TRIGGER` statement.
+type DropTriggerBuilder struct {
+	Builder
+	name   string
+	table  string
+	exists bool
+}
+
+// DropTrigger creates a builder for the `DROP TRIGGER` statement.
+//
+//	PostgreSQL:
+//
+//		DropTrigger("users_updated_at").
+//			On("users")
+//
+//	MySQL/SQLite:
+//
+//		DropTrigger("users_updated_at")
+//
+func DropTrigger(name string) *DropTriggerBuilder {
+	return &DropTriggerBuilder{name: name}
+}
+
+// On sets the table of the trigger. PostgreSQL only.
+func (d *DropTriggerBuilder) On(table string) *DropTriggerBuilder {
+	d.table = table
+	return d
+}
+
+// IfExists appends the `IF EXISTS` clause to the `DROP TRIGGER` statement.
+func (d *DropTriggerBuilder) IfExists() *DropTriggerBuilder {
+	d.exists = true
+	return d
+}
+
+// Query returns query representation of a `DROP TRIGGER` statement.
+//
+//	DROP TRIGGER [IF EXISTS] name [ON table]
+//
+func (d *DropTriggerBuilder) Query() (string, []interface{}) {
+	d.WriteString("DROP TRIGGER ")
+	if d.exists {
+		d.WriteString("IF EXISTS ")
+	}
+	d.Ident(d.name)
+	if d.postgres() {
+		if d.table == "" {
+			d.AddError(fmt.Errorf("sql: missing table for trigger %q", d.name))
+		}
+		d.WriteString(" ON ")
+		d.Ident(d.table)
+	}
+	return d.String(), nil
+}
+
 // InsertBuilder is a builder for `INSERT INTO` statement.
 type InsertBuilder struct {
 	Builder
@@ -721,6 +1180,7 @@ func (i *InsertBuilder) Default() *InsertBuilder {
 }
 
 // Returning adds the `RETURNING` clause to the insert statement. PostgreSQL only.
+// In SQL Server, the columns are returned by the `OUTPUT` clause.
 func (i *InsertBuilder) Returning(columns ...string) *InsertBuilder {
 	i.returning = columns
 	return i
@@ -953,7 +1413,7 @@ func (u *UpdateSet) SetIgnore(name string) *UpdateSet {
 // SetExcluded sets the column name to its EXCLUDED/VALUES value.
 // For example, "c" = "excluded"."c", or `c` = VALUES(`c`).
 func (u *UpdateSet) SetExcluded(name string) *UpdateSet {
-	switch u.update.Dialect() {
+	switch u.update.family() {
 	case dialect.MySQL:
 		u.update.Set(name, ExprFunc(func(b *Builder) {
 			b.WriteString("VALUES(").Ident(name).WriteChar(')')
@@ -967,13 +1427,24 @@ func (u *UpdateSet) SetExcluded(name string) *UpdateSet {
 
 // Query returns query representation of an `INSERT INTO` statement.
 func (i *InsertBuilder) Query() (string, []interface{}) {
+	if i.conflict != nil && i.upsert() == dialect.UpsertMerge {
+		i.writeMerge()
+		return i.String(), i.args
+	}
 	i.WriteString("INSERT INTO ")
 	i.writeSchema(i.schema)
 	i.Ident(i.table).Pad()
 	if i.defaults && len(i.columns) == 0 {
+		if len(i.returning) > 0 && i.sqlserver() {
+			i.writeOutput("INSERTED", i.returning)
+			i.Pad()
+		}
 		i.writeDefault()
 	} else {
 		i.WriteChar('(').IdentComma(i.columns...).WriteChar(')')
+		if len(i.returning) > 0 && i.sqlserver() {
+			i.Pad().writeOutput("INSERTED", i.returning)
+		}
 		i.WriteString(" VALUES ")
 		for j, v := range i.values {
 			if j > 0 {
@@ -985,7 +1456,7 @@ func (i *InsertBuilder) Query() (string, []interface{}) {
 	if i.conflict != nil {
 		i.writeConflict()
 	}
-	if len(i.returning) > 0 && !i.mysql() {
+	if len(i.returning) > 0 && i.supports(dialect.FeatureReturning) {
 		i.WriteString(" RETURNING ")
 		i.IdentComma(i.returning...)
 	}
@@ -993,22 +1464,22 @@ func (i *InsertBuilder) Query() (string, []interface{}) {
 }
 
 func (i *InsertBuilder) writeDefault() {
-	switch i.Dialect() {
+	switch i.family() {
 	case dialect.MySQL:
 		i.WriteString("VALUES ()")
-	case dialect.SQLite, dialect.Postgres:
+	case dialect.SQLite, dialect.Postgres, dialect.SQLServer:
 		i.WriteString("DEFAULT VALUES")
 	}
 }
 
 func (i *InsertBuilder) writeConflict() {
-	switch i.Dialect() {
-	case dialect.MySQL:
+	switch i.upsert() {
+	case dialect.UpsertOnDuplicateKey:
 		i.WriteString(" ON DUPLICATE KEY UPDATE ")
 		if i.conflict.action.nothing {
 			i.AddError(fmt.Errorf("invalid CONFLICT action ('DO NOTHING')"))
 		}
-	case dialect.SQLite, dialect.Postgres:
+	case dialect.UpsertOnConflict:
 		i.WriteString(" ON CONFLICT")
 		switch t := i.conflict.target; {
 		case t.constraint != "" && len(t.columns) != 0:
@@ -1026,7 +1497,22 @@ func (i *InsertBuilder) writeConflict() {
 			return
 		}
 		i.WriteString(" DO UPDATE SET ")
+	default:
+		// the unknown dialects get only the changes of the conflict action
+		if _, ok := dialect.Lookup(i.Dialect()); ok {
+			i.AddError(fmt.Errorf("sql: INSERT .. ON CONFLICT not supported in %s", i.Dialect()))
+			return
+		}
 	}
+	i.writeUpdateSet()
+	if p := i.conflict.action.where; p != nil {
+		p.qualifier = i.table
+		i.WriteString(" WHERE ").Join(p)
+	}
+}
+
+// writeUpdateSet writes the changes of the conflict action.
+func (i *InsertBuilder) writeUpdateSet() {
 	if len(i.conflict.action.update) == 0 {
 		i.AddError(errors.New("missing action for 'DO UPDATE SET' clause"))
 	}
@@ -1036,10 +1522,65 @@ func (i *InsertBuilder) writeConflict() {
 		f(u)
 	}
 	u.update.writeSetter(&i.Builder)
-	if p := i.conflict.action.where; p != nil {
-		p.qualifier = i.table
-		i.WriteString(" WHERE ").Join(p)
+}
+
+// writeMerge writes the `MERGE` statement that performs the upsert in SQL
+// Server. The proposed rows are named "excluded" like in PostgreSQL, so the
+// conflict actions reference them in the same way.
+func (i *InsertBuilder) writeMerge() {
+	switch t := i.conflict.target; {
+	case t.constraint != "":
+		i.AddError(fmt.Errorf("sql: MERGE does not support the conflict constraint %q", t.constraint))
+	case t.where != nil:
+		i.AddError(errors.New("sql: MERGE does not support the conflict WHERE clause"))
+	case len(t.columns) == 0:
+		i.AddError(errors.New("sql: MERGE requires the conflict columns"))
+	}
+	if len(i.values) == 0 {
+		i.AddError(errors.New("sql: MERGE requires the values to insert"))
+	}
+	var (
+		b        = Dialect(i.dialect)
+		table    = b.Table(i.table)
+		excluded = b.Table("excluded")
+	)
+	i.WriteString("MERGE INTO ")
+	i.writeSchema(i.schema)
+	i.Ident(i.table).WriteString(" USING (VALUES ")
+	for j, v := range i.values {
+		if j > 0 {
+			i.Comma()
+		}
+		i.WriteChar('(').Args(v...).WriteChar(')')
 	}
+	i.WriteString(") AS ").Ident("excluded").WriteString(" (").IdentComma(i.columns...).WriteString(") ON ")
+	for j, c := range i.conflict.target.columns {
+		if j > 0 {
+			i.WriteString(" AND ")
+		}
+		i.WriteString(table.C(c)).WriteOp(OpEQ).WriteString(excluded.C(c))
+	}
+	if !i.conflict.action.nothing {
+		i.WriteString(" WHEN MATCHED")
+		if p := i.conflict.action.where; p != nil {
+			p.qualifier = i.table
+			i.WriteString(" AND ").Join(p)
+		}
+		i.WriteString(" THEN UPDATE SET ")
+		i.writeUpdateSet()
+	}
+	i.WriteString(" WHEN NOT MATCHED THEN INSERT (").IdentComma(i.columns...).WriteString(") VALUES (")
+	for j, c := range i.columns {
+		if j > 0 {
+			i.Comma()
+		}
+		i.WriteString(excluded.C(c))
+	}
+	i.WriteChar(')')
+	if len(i.returning) > 0 {
+		i.Pad().writeOutput("INSERTED", i.returning)
+	}
+	i.WriteChar(';')
 }
 
 // UpdateBuilder is a builder for `UPDATE` statement.
@@ -1145,11 +1686,14 @@ func (u *UpdateBuilder) Query() (string, []interface{}) {
 	b.writeSchema(u.schema)
 	b.Ident(u.table).WriteString(" SET ")
 	u.writeSetter(&b)
+	if len(u.returning) > 0 && u.sqlserver() {
+		b.Pad().writeOutput("INSERTED", u.returning)
+	}
 	if u.where != nil {
 		b.WriteString(" WHERE ")
 		b.Join(u.where)
 	}
-	if len(u.returning) > 0 && !u.mysql() {
+	if len(u.returning) > 0 && u.supports(dialect.FeatureReturning) {
 		b.WriteString(" RETURNING ")
 		b.IdentComma(u.returning...)
 	}
@@ -1249,11 +1793,14 @@ func (d *DeleteBuilder) Query() (string, []interface{}) {
 	d.WriteString("DELETE FROM ")
 	d.writeSchema(d.schema)
 	d.Ident(d.table)
+	if len(d.returning) > 0 && d.sqlserver() {
+		d.Pad().writeOutput("DELETED", d.returning)
+	}
 	if d.where != nil {
 		d.WriteString(" WHERE ")
 		d.Join(d.where)
 	}
-	if len(d.returning) > 0 && !d.mysql() {
+	if len(d.returning) > 0 && d.supports(dialect.FeatureReturning) {
 		d.WriteString(" RETURNING ")
 		d.IdentComma(d.returning...)
 	}
@@ -1665,7 +2212,7 @@ func (p *Predicate) escapedLike(col, left, right, word string) *Predicate {
 		w, escaped := escape(word)
 		b.Ident(col).WriteOp(OpLike)
 		b.Arg(left + w + right)
-		if p.dialect == dialect.SQLite && escaped {
+		if p.sqlite() && escaped {
 			p.WriteString(" ESCAPE ").Arg("\\")
 		}
 	})
@@ -1697,7 +2244,7 @@ func (p *Predicate) EqualFold(col, sub string) *Predicate {
 	return p.Append(func(b *Builder) {
 		f := &Func{}
 		f.SetDialect(b.dialect)
-		switch b.dialect {
+		switch b.family() {
 		case dialect.MySQL:
 			// We assume the CHARACTER SET is configured to utf8mb4,
 			// because this how it is defined in dialect/sql/schema.
@@ -1728,7 +2275,7 @@ func ContainsFold(col, sub string) *Predicate { return P().ContainsFold(col, sub
 func (p *Predicate) ContainsFold(col, substr string) *Predicate {
 	return p.Append(func(b *Builder) {
 		w, escaped := escape(substr)
-		switch b.dialect {
+		switch b.family() {
 		case dialect.MySQL:
 			// We assume the CHARACTER SET is configured to utf8mb4,
 			// because this how it is defined in dialect/sql/schema.
@@ -2507,8 +3054,11 @@ func WithLockClause(clause string) LockOption {
 // For sets the lock configuration for suffixing the `SELECT`
 // statement with the `FOR [SHARE | UPDATE] ...` clause.
 func (s *Selector) For(l LockStrength, opts ...LockOption) *Selector {
-	if s.Dialect() == dialect.SQLite {
+	switch {
+	case s.sqlite():
 		s.AddError(errors.New("sql: SELECT .. FOR UPDATE/SHARE not supported in SQLite"))
+	case !s.supports(dialect.FeatureLock):
+		s.AddError(fmt.Errorf("sql: SELECT .. FOR UPDATE/SHARE not supported in %s", s.Dialect()))
 	}
 	s.lock = &LockOptions{Strength: l}
 	for _, opt := range opts {
@@ -2609,6 +3159,9 @@ func (s *Selector) Query() (string, []interface{}) {
 	if s.distinct {
 		b.WriteString("DISTINCT ")
 	}
+	if s.top() {
+		b.WriteString("TOP (").WriteString(strconv.Itoa(*s.limit)).WriteString(") ")
+	}
 	if len(s.selection) > 0 {
 		s.joinSelect(&b)
 	} else {
@@ -2670,13 +3223,8 @@ func (s *Selector) Query() (string, []interface{}) {
 	if len(s.order) > 0 {
 		s.joinOrder(&b)
 	}
-	if s.limit != nil {
-		b.WriteString(" LIMIT ")
-		b.WriteString(strconv.Itoa(*s.limit))
-	}
-	if s.offset != nil {
-		b.WriteString(" OFFSET ")
-		b.WriteString(strconv.Itoa(*s.offset))
+	if s.limit != nil || s.offset != nil {
+		s.joinLimit(&b)
 	}
 	s.joinLock(&b)
 	s.total = b.total
@@ -2691,6 +3239,35 @@ func (s *Selector) joinPrefix(b *Builder) {
 	}
 }
 
+// top reports if the limit is written as the `TOP` clause of SQL Server.
+// The `OFFSET ... FETCH` clause is used when the rows are ordered or
+// skipped.
+func (s *Selector) top() bool {
+	return s.sqlserver() && s.limit != nil && s.offset == nil && len(s.order) == 0 && len(s.union) == 0
+}
+
+func (s *Selector) joinLimit(b *Builder) {
+	if s.top() {
+		return
+	}
+	if s.sqlserver() && len(s.order) == 0 {
+		// OFFSET and FETCH are allowed only after ORDER BY.
+		b.WriteString(" ORDER BY (SELECT NULL)")
+	}
+	if d, ok := dialect.Lookup(s.dialect); ok {
+		b.Pad().WriteString(d.Limit(s.limit, s.offset))
+		return
+	}
+	if s.limit != nil {
+		b.WriteString(" LIMIT ")
+		b.WriteString(strconv.Itoa(*s.limit))
+	}
+	if s.offset != nil {
+		b.WriteString(" OFFSET ")
+		b.WriteString(strconv.Itoa(*s.offset))
+	}
+}
+
 func (s *Selector) joinLock(b *Builder) {
 	if s.lock == nil {
 		return
@@ -2947,20 +3524,14 @@ type Builder struct {
 // Quote quotes the given identifier with the characters based
 // on the configured dialect. It defaults to "`".
 func (b *Builder) Quote(ident string) string {
-	quote := "`"
-	switch {
-	case b.postgres():
-		// If it was quoted with the wrong
-		// identifier character.
-		if strings.Contains(ident, "`") {
-			return strings.ReplaceAll(ident, "`", `"`)
-		}
-		quote = `"`
+	if d, ok := dialect.Lookup(b.dialect); ok {
+		return d.Quote(ident)
+	}
 	// An identifier for unknown dialect.
-	case b.dialect == "" && strings.ContainsAny(ident, "`\""):
+	if b.dialect == "" && strings.ContainsAny(ident, "`\"") {
 		return ident
 	}
-	return quote + ident + quote
+	return "`" + ident + "`"
 }
 
 // Ident appends the given string as an identifier.
@@ -2972,10 +3543,13 @@ func (b *Builder) Ident(s string) *Builder {
 			b.WriteString(b.Quote(b.qualifier)).WriteChar('.')
 		}
 		b.WriteString(b.Quote(s))
-	case (isFunc(s) || isModifier(s)) && b.postgres():
+	case (isFunc(s) || isModifier(s)) && (b.postgres() || b.sqlserver()):
 		// Modifiers and aggregation functions that
 		// were called without dialect information.
-		b.WriteString(strings.ReplaceAll(s, "`", `"`))
+		if strings.Contains(s, "`") {
+			s = b.Quote(s)
+		}
+		b.WriteString(s)
 	default:
 		b.WriteString(s)
 	}
@@ -3045,7 +3619,7 @@ func (b *Builder) AddError(err error) *Builder {
 }
 
 func (b *Builder) writeSchema(schema string) {
-	if schema != "" && b.dialect != dialect.SQLite {
+	if schema != "" && b.supports(dialect.FeatureSchema) {
 		b.Ident(schema).WriteChar('.')
 	}
 }
@@ -3154,10 +3728,10 @@ func (b *Builder) Arg(a interface{}) *Builder {
 	b.args = append(b.args, a)
 	// Default placeholder param (MySQL and SQLite).
 	param := "?"
-	if b.postgres() {
+	if d, ok := dialect.Lookup(b.dialect); ok {
 		// PostgreSQL arguments are referenced using the syntax $n.
 		// $1 refers to the 1st argument, $2 to the 2nd, and so on.
-		param = "$" + strconv.Itoa(b.total)
+		param = d.Placeholder().Format(b.total)
 	}
 	if f, ok := a.(ParamFormatter); ok {
 		param = f.FormatParam(param, &StmtInfo{
@@ -3273,14 +3847,60 @@ func (b Builder) clone() Builder {
 	return c
 }
 
+// family returns the family of the builder dialect. The dialects
+// that follow the PostgreSQL syntax (e.g. CockroachDB) are rendered
+// as PostgreSQL.
+func (b Builder) family() string {
+	return dialect.FamilyOf(b.Dialect())
+}
+
 // postgres reports if the builder dialect is PostgreSQL.
 func (b Builder) postgres() bool {
-	return b.Dialect() == dialect.Postgres
+	return b.family() == dialect.Postgres
 }
 
 // mysql reports if the builder dialect is MySQL.
 func (b Builder) mysql() bool {
-	return b.Dialect() == dialect.MySQL
+	return b.family() == dialect.MySQL
+}
+
+// sqlite reports if the builder dialect is SQLite.
+func (b Builder) sqlite() bool {
+	return b.family() == dialect.SQLite
+}
+
+// sqlserver reports if the builder dialect is SQL Server.
+func (b Builder) sqlserver() bool {
+	return b.family() == dialect.SQLServer
+}
+
+// writeOutput writes the `OUTPUT` clause that replaces the `RETURNING`
+// clause in SQL Server. The table is either INSERTED or DELETED.
+func (b *Builder) writeOutput(table string, columns []string) {
+	b.WriteString("OUTPUT ")
+	for i, c := range columns {
+		if i > 0 {
+			b.Comma()
+		}
+		b.WriteString(table).WriteChar('.').Ident(c)
+	}
+}
+
+// upsert returns the upsert syntax of the builder dialect.
+func (b Builder) upsert() dialect.Upsert {
+	if d, ok := dialect.Lookup(b.Dialect()); ok {
+		return d.Upsert()
+	}
+	return dialect.UpsertNone
+}
+
+// supports reports if the builder dialect supports the given feature.
+// The unknown dialects are assumed to support all of them.
+func (b Builder) supports(feature dialect.Feature) bool {
+	if d, ok := dialect.Lookup(b.Dialect()); ok {
+		return d.Supports(feature)
+	}
+	return true
 }
 
 // fromIdent sets the builder dialect from the identifier format.
@@ -3293,12 +3913,10 @@ func (b *Builder) fromIdent(ident string) {
 
 // isIdent reports if the given string is a dialect identifier.
 func (b *Builder) isIdent(s string) bool {
-	switch {
-	case b.postgres():
-		return strings.Contains(s, `"`)
-	default:
-		return strings.Contains(s, "`")
+	if d, ok := dialect.Lookup(b.dialect); ok {
+		return strings.Contains(s, d.Quote("")[:1])
 	}
+	return strings.Contains(s, "`")
 }
 
 // state wraps the all methods for setting and getting
@@ -3491,6 +4109,83 @@ func (d *DialectBuilder) DropIndex(name string) *DropIndexBuilder {
 	return b
 }
 
+// DropTable creates a DropTableBuilder for the configured dialect.
+//
+//	Dialect(dialect.Postgres).
+//		DropTable("users").
+//		IfExists().
+//		Cascade()
+//
+func (d *DialectBuilder) DropTable(names ...string) *DropTableBuilder {
+	b := DropTable(names...)
+	b.SetDialect(d.dialect)
+	return b
+}
+
+// Truncate creates a TruncateBuilder for the configured dialect.
+//
+//	Dialect(dialect.Postgres).
+//		Truncate("users").
+//		RestartIdentity()
+//
+func (d *DialectBuilder) Truncate(name string) *TruncateBuilder {
+	b := Truncate(name)
+	b.SetDialect(d.dialect)
+	return b
+}
+
+// CreateView creates a ViewBuilder for the configured dialect.
+//
+//	Dialect(dialect.Postgres).
+//		CreateView("active_users").
+//		OrReplace().
+//		As(Select().From(Table("users")).Where(EQ("active", true)))
+//
+func (d *DialectBuilder) CreateView(name string) *ViewBuilder {
+	b := CreateView(name)
+	b.SetDialect(d.dialect)
+	return b
+}
+
+// DropView creates a DropViewBuilder for the configured dialect.
+//
+//	Dialect(dialect.Postgres).
+//		DropView("active_users").
+//		IfExists()
+//
+func (d *DialectBuilder) DropView(names ...string) *DropViewBuilder {
+	b := DropView(names...)
+	b.SetDialect(d.dialect)
+	return b
+}
+
+// CreateTrigger creates a TriggerBuilder for the configured dialect.
+//
+//	Dialect(dialect.Postgres).
+//		CreateTrigger("users_updated_at").
+//		Before("UPDATE").
+//		On("users").
+//		ForEachRow().
+//		Execute("set_updated_at")
+//
+func (d *DialectBuilder) CreateTrigger(name string) *TriggerBuilder {
+	b := CreateTrigger(name)
+	b.SetDialect(d.dialect)
+	return b
+}
+
+// DropTrigger creates a DropTriggerBuilder for the configured dialect.
+//
+//	Dialect(dialect.Postgres).
+//		DropTrigger("users_updated_at").
+//		On("users")
+//
+func (d *DialectBuilder) DropTrigger(name string) *DropTriggerBuilder {
+	b := DropTrigger(name)
+	b.SetDialect(d.dialect)
+	return b
+}
+
 func isFunc(s string) bool {
 	return strings.Contains(s, "(") && strings.Contains(s, ")")
 }
@@ -1413,6 +1413,125 @@ func TestBuilder(t *testing.T) {
 			input:     DropIndex("name_index").Table("users"),
 			wantQuery: "DROP INDEX `name_index` ON `users`",
 		},
+		{
+			input:     Savepoint("sp1"),
+			wantQuery: "SAVEPOINT `sp1`",
+		},
+		{
+			input:     RollbackToSavepoint("sp1"),
+			wantQuery: "ROLLBACK TO SAVEPOINT `sp1`",
+		},
+		{
+			input: func() Querier {
+				b := ReleaseSavepoint("sp1")
+				b.SetDialect(dialect.Postgres)
+				return b
+			}(),
+			wantQuery: `RELEASE SAVEPOINT "sp1"`,
+		},
+		{
+			input:     DropTable("users", "groups"),
+			wantQuery: "DROP TABLE `users`, `groups`",
+		},
+		{
+			input: Dialect(dialect.Postgres).
+				DropTable("users").
+				IfExists().
+				Cascade(),
+			wantQuery: `DROP TABLE IF EXISTS "users" CASCADE`,
+		},
+		{
+			input: Dialect(dialect.SQLite).
+				DropTable("users").
+				IfExists().
+				Cascade(),
+			wantQuery: "DROP TABLE IF EXISTS `users`",
+		},
+		{
+			input:     Dialect(dialect.MySQL).Truncate("users"),
+			wantQuery: "TRUNCATE TABLE `users`",
+		},
+		{
+			input: Dialect(dialect.Postgres).
+				Truncate("users").
+				RestartIdentity().
+				Cascade(),
+			wantQuery: `TRUNCATE TABLE "users" RESTART IDENTITY CASCADE`,
+		},
+		{
+			input:     Dialect(dialect.SQLite).Truncate("users"),
+			wantQuery: "DELETE FROM `users`",
+		},
+		{
+			input: Dialect(dialect.Postgres).
+				CreateView("active_users").
+				OrReplace().
+				Columns("id", "name").
+				As(Select("id", "name").From(Table("users")).Where(EQ("active", true))),
+			wantQuery: `CREATE OR REPLACE VIEW "active_users"("id", "name") AS SELECT "id", "name" FROM "users" WHERE "active" = $1`,
+			wantArgs:  []interface{}{true},
+		},
+		{
+			input: Dialect(dialect.SQLite).
+				CreateView("active_users").
+				IfNotExists().
+				As(Select().From(Table("users")).Where(ExprP("active"))),
+			wantQuery: "CREATE VIEW IF NOT EXISTS `active_users` AS SELECT * FROM `users` WHERE active",
+		},
+		{
+			input:     DropView("active_users").IfExists(),
+			wantQuery: "DROP VIEW IF EXISTS `active_users`",
+		},
+		{
+			input: Dialect(dialect.Postgres).
+				DropView("active_users").
+				Cascade(),
+			wantQuery: `DROP VIEW "active_users" CASCADE`,
+		},
+		{
+			input: Dialect(dialect.Postgres).
+				CreateTrigger("users_updated_at").
+				Before("INSERT", "UPDATE").
+				On("users").
+				ForEachRow().
+				When(Expr("NEW.name IS NOT NULL")).
+				Execute("set_updated_at"),
+			wantQuery: `CREATE TRIGGER "users_updated_at" BEFORE INSERT OR UPDATE ON "users" FOR EACH ROW WHEN (NEW.name IS NOT NULL) EXECUTE FUNCTION set_updated_at()`,
+		},
+		{
+			input: Dialect(dialect.MySQL).
+				CreateTrigger("users_updated_at").
+				Before("UPDATE").
+				On("users").
+				ForEachRow().
+				Do(Expr("SET NEW.updated_at = CURRENT_TIMESTAMP")),
+			wantQuery: "CREATE TRIGGER `users_updated_at` BEFORE UPDATE ON `users` FOR EACH ROW SET NEW.updated_at = CURRENT_TIMESTAMP",
+		},
+		{
+			input: Dialect(dialect.SQLite).
+				CreateTrigger("users_updated_at").
+				After("UPDATE").
+				On("users").
+				ForEachRow().
+				Do(
+					Update("users").Set("updated_at", Expr("CURRENT_TIMESTAMP")).Where(ExprP("id = NEW.id")),
+					Delete("sessions").Where(ExprP("user_id = NEW.id")),
+				),
+			wantQuery: "CREATE TRIGGER `users_updated_at` AFTER UPDATE ON `users` FOR EACH ROW BEGIN " +
+				"UPDATE `users` SET `updated_at` = CURRENT_TIMESTAMP WHERE id = NEW.id; " +
+				"DELETE FROM `sessions` WHERE user_id = NEW.id; END",
+		},
+		{
+			input: Dialect(dialect.Postgres).
+				DropTrigger("users_updated_at").
+				IfExists().
+				On("users"),
+			wantQuery: `DROP TRIGGER IF EXISTS "users_updated_at" ON "users"`,
+		},
+		{
+			input:     Dialect(dialect.SQLite).DropTrigger("users_updated_at"),
+			wantQuery: "DROP TRIGGER `users_updated_at`",
+		},
 		{
 			input: Select().
 				From(Table("pragma_table_info('t1')").Unquote()).
@@ -1553,6 +1672,195 @@ AND "users"."id1" < "users"."id2") AND "users"."id1" <= "users"."id2"`, "\n", ""
 	}
 }
 
+func TestBuilder_SQLServer(t *testing.T) {
+	tests := []struct {
+		input     Querier
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			input:     Dialect(dialect.SQLServer).Select("id", "name").From(Table("users")).Where(EQ("id", 1)),
+			wantQuery: "SELECT [id], [name] FROM [users] WHERE [id] = @p1",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select().From(Table("users")).Where(GT("age", 18)).Limit(10),
+			wantQuery: "SELECT TOP (10) * FROM [users] WHERE [age] > @p1",
+			wantArgs:  []interface{}{18},
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select().Distinct().From(Table("users")).Limit(1),
+			wantQuery: "SELECT DISTINCT TOP (1) * FROM [users]",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select().From(Table("users")).OrderBy("name").Limit(10).Offset(20),
+			wantQuery: "SELECT * FROM [users] ORDER BY [name] OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select().From(Table("users")).OrderBy(Desc("name")).Limit(10),
+			wantQuery: "SELECT * FROM [users] ORDER BY [name] DESC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select().From(Table("users")).Offset(5),
+			wantQuery: "SELECT * FROM [users] ORDER BY (SELECT NULL) OFFSET 5 ROWS",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select(Count("*")).From(Table("users").Schema("app")),
+			wantQuery: "SELECT COUNT(*) FROM [app].[users]",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Select().From(Table("odd]name")),
+			wantQuery: "SELECT * FROM [odd]]name]",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Insert("users").Columns("name", "age").Values("a8m", 10).Values("foo", 20),
+			wantQuery: "INSERT INTO [users] ([name], [age]) VALUES (@p1, @p2), (@p3, @p4)",
+			wantArgs:  []interface{}{"a8m", 10, "foo", 20},
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Insert("users").Columns("name").Values("a8m").Returning("id", "name"),
+			wantQuery: "INSERT INTO [users] ([name]) OUTPUT INSERTED.[id], INSERTED.[name] VALUES (@p1)",
+			wantArgs:  []interface{}{"a8m"},
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Insert("users").Default().Returning("*"),
+			wantQuery: "INSERT INTO [users] OUTPUT INSERTED.* DEFAULT VALUES",
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Update("users").Set("name", "foo").Where(EQ("id", 1)).Returning("id"),
+			wantQuery: "UPDATE [users] SET [name] = @p1 OUTPUT INSERTED.[id] WHERE [id] = @p2",
+			wantArgs:  []interface{}{"foo", 1},
+		},
+		{
+			input:     Dialect(dialect.SQLServer).Delete("users").Where(EQ("id", 1)).Returning("id"),
+			wantQuery: "DELETE FROM [users] OUTPUT DELETED.[id] WHERE [id] = @p1",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			input: Dialect(dialect.SQLServer).
+				Insert("users").
+				Columns("id", "email", "creation_time").
+				Values("1", "user@example.com", 1633279231).
+				OnConflict(
+					ConflictColumns("email"),
+					ResolveWithNewValues(),
+					ResolveWith(func(u *UpdateSet) {
+						u.SetIgnore("id")
+						u.SetIgnore("creation_time")
+						u.Add("version", 1)
+					}),
+					UpdateWhere(NEQ("updated_at", 0)),
+				).
+				Returning("id"),
+			wantQuery: "MERGE INTO [users] USING (VALUES (@p1, @p2, @p3)) AS [excluded] ([id], [email], [creation_time]) " +
+				"ON [users].[email] = [excluded].[email] " +
+				"WHEN MATCHED AND [users].[updated_at] <> @p4 THEN UPDATE SET [id] = [users].[id], [email] = [excluded].[email], [creation_time] = [users].[creation_time], [version] = COALESCE([users].[version], 0) + @p5 " +
+				"WHEN NOT MATCHED THEN INSERT ([id], [email], [creation_time]) VALUES ([excluded].[id], [excluded].[email], [excluded].[creation_time]) " +
+				"OUTPUT INSERTED.[id];",
+			wantArgs: []interface{}{"1", "user@example.com", 1633279231, 0, 1},
+		},
+		{
+			input: Dialect(dialect.SQLServer).
+				Insert("users").
+				Schema("app").
+				Columns("id", "name").
+				Values(1, "a8m").
+				Values(2, "foo").
+				OnConflict(
+					ConflictColumns("id"),
+					DoNothing(),
+				),
+			wantQuery: "MERGE INTO [app].[users] USING (VALUES (@p1, @p2), (@p3, @p4)) AS [excluded] ([id], [name]) " +
+				"ON [users].[id] = [excluded].[id] " +
+				"WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES ([excluded].[id], [excluded].[name]);",
+			wantArgs: []interface{}{1, "a8m", 2, "foo"},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			query, args := tt.input.Query()
+			require.Equal(t, tt.wantQuery, query)
+			require.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestBuilder_SQLServerErr(t *testing.T) {
+	tests := []struct {
+		input   querierErr
+		wantErr string
+	}{
+		{
+			input:   Dialect(dialect.SQLServer).Insert("users").Columns("id").Values(1).OnConflict(ConflictConstraint("users_pkey"), DoNothing()),
+			wantErr: `sql: MERGE does not support the conflict constraint "users_pkey"`,
+		},
+		{
+			input:   Dialect(dialect.SQLServer).Insert("users").Columns("id").Values(1).OnConflict(DoNothing()),
+			wantErr: "sql: MERGE requires the conflict columns",
+		},
+		{
+			input:   Dialect(dialect.SQLServer).Select().From(Table("users")).ForUpdate(),
+			wantErr: "sql: SELECT .. FOR UPDATE/SHARE not supported in sqlserver",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tt.input.(Querier).Query()
+			require.EqualError(t, tt.input.Err(), tt.wantErr)
+		})
+	}
+}
+
+func TestDDLBuilder_Err(t *testing.T) {
+	tests := []struct {
+		input   querierErr
+		wantErr string
+	}{
+		{
+			input:   Dialect(dialect.SQLite).CreateView("v").OrReplace().As(Select().From(Table("users"))),
+			wantErr: "sql: CREATE OR REPLACE VIEW not supported in SQLite",
+		},
+		{
+			input:   Dialect(dialect.MySQL).CreateView("v").IfNotExists().As(Select().From(Table("users"))),
+			wantErr: "sql: CREATE VIEW IF NOT EXISTS supported only in SQLite",
+		},
+		{
+			input:   Dialect(dialect.Postgres).CreateView("v"),
+			wantErr: `sql: missing query for view "v"`,
+		},
+		{
+			input:   Dialect(dialect.SQLite).CreateTrigger("t").On("users").Do(Delete("users")),
+			wantErr: `sql: missing event for trigger "t"`,
+		},
+		{
+			input:   Dialect(dialect.MySQL).CreateTrigger("t").After("INSERT", "UPDATE").On("users").Do(Delete("users")),
+			wantErr: `sql: multiple events for trigger "t" supported only in PostgreSQL`,
+		},
+		{
+			input:   Dialect(dialect.MySQL).CreateTrigger("t").InsteadOf("INSERT").On("users").Do(Delete("users")),
+			wantErr: "sql: INSTEAD OF triggers not supported in MySQL",
+		},
+		{
+			input:   Dialect(dialect.Postgres).CreateTrigger("t").After("INSERT").On("users").Do(Delete("users")),
+			wantErr: `sql: missing function for trigger "t"`,
+		},
+		{
+			input:   Dialect(dialect.SQLite).CreateTrigger("t").After("INSERT").On("users").Execute("f"),
+			wantErr: `sql: missing statements for trigger "t"`,
+		},
+		{
+			input:   Dialect(dialect.Postgres).DropTrigger("t"),
+			wantErr: `sql: missing table for trigger "t"`,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tt.input.(Querier).Query()
+			require.EqualError(t, tt.input.Err(), tt.wantErr)
+		})
+	}
+}
+
 func TestBuilder_Err(t *testing.T) {
 	b := Select("i-")
 	require.NoError(t, b.Err())
@@ -1762,6 +2070,82 @@ func TestSelectWithLock(t *testing.T) {
 	require.EqualError(t, s.Err(), "sql: SELECT .. FOR UPDATE/SHARE not supported in SQLite")
 }
 
+type cockroach struct {
+	dialect.Dialect
+}
+
+func (cockroach) Name() string {
+	return "cockroach"
+}
+
+func TestBuilder_RegisteredDialect(t *testing.T) {
+	dialect.Register("cockroach", &cockroach{dialect.MustLookup(dialect.Postgres)})
+
+	query, args := Dialect("cockroach").
+		Select().
+		From(Table("users").Schema("app")).
+		Where(EQ("id", 1)).
+		Limit(1).
+		ForUpdate().
+		Query()
+	require.Equal(t, `SELECT * FROM "app"."users" WHERE "id" = $1 LIMIT 1 FOR UPDATE`, query)
+	require.Equal(t, []interface{}{1}, args)
+
+	query, args = Dialect("cockroach").
+		Insert("users").
+		Columns("id", "name").
+		Values(1, "a8m").
+		OnConflict(
+			ConflictColumns("id"),
+			ResolveWithNewValues(),
+		).
+		Returning("id").
+		Query()
+	require.Equal(t, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "id" = "excluded"."id", "name" = "excluded"."name" RETURNING "id"`, query)
+	require.Equal(t, []interface{}{1, "a8m"}, args)
+}
+
+type noUpsert struct {
+	dialect.Dialect
+}
+
+func (noUpsert) Name() string {
+	return "noupsert"
+}
+
+func (noUpsert) Upsert() dialect.Upsert {
+	return dialect.UpsertNone
+}
+
+func TestInsert_OnConflictNotSupported(t *testing.T) {
+	dialect.Register("noupsert", &noUpsert{dialect.MustLookup(dialect.SQLite)})
+
+	b := Dialect("noupsert").
+		Insert("users").
+		Columns("id", "name").
+		Values(1, "a8m").
+		OnConflict(
+			ConflictColumns("id"),
+			ResolveWithNewValues(),
+		)
+	b.Query()
+	require.EqualError(t, b.Err(), "sql: INSERT .. ON CONFLICT not supported in noupsert")
+}
+
+func TestInsert_OnConflictWithoutDialect(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name").
+		Values(1, "a8m").
+		OnConflict(
+			ConflictColumns("id"),
+			ResolveWithNewValues(),
+		)
+	query, args := b.Query()
+	require.NoError(t, b.Err())
+	require.Equal(t, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?)`id` = `excluded`.`id`, `name` = `excluded`.`name`", query)
+	require.Equal(t, []interface{}{1, "a8m"}, args)
+}
+
 func TestSelector_UnionOrderBy(t *testing.T) {
 	table := Table("users")
 	query, _ := Dialect(dialect.Postgres).
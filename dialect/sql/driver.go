@@ -9,12 +9,14 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
-	"strings"
 
 	"github.com/phogolabs/orm/dialect"
 )
 
-var _ dialect.Driver = (*Driver)(nil)
+var (
+	_ dialect.Driver       = (*Driver)(nil)
+	_ dialect.RowsObserver = (*Rows)(nil)
+)
 
 // Driver is a dialect.Driver implementation for SQL based databases.
 type Driver struct {
@@ -32,7 +34,7 @@ func Open(name, source string) (*Driver, error) {
 	}
 
 	driver := &Driver{
-		ExecQuerier: &Conn{db},
+		ExecQuerier: &Conn{ExecQuerier: db},
 		name:        name,
 	}
 
@@ -42,7 +44,7 @@ func Open(name, source string) (*Driver, error) {
 // OpenDB wraps the given database/sql.DB method with a Driver.
 func OpenDB(name string, db *sql.DB) *Driver {
 	driver := &Driver{
-		ExecQuerier: &Conn{db},
+		ExecQuerier: &Conn{ExecQuerier: db},
 		name:        name,
 	}
 
@@ -51,18 +53,39 @@ func OpenDB(name string, db *sql.DB) *Driver {
 
 // DB returns the underlying *sql.DB instance.
 func (d Driver) DB() *sql.DB {
-	conn := d.ExecQuerier.(*Conn)
+	conn := d.conn()
 	// the underlying database
 	return conn.ExecQuerier.(*sql.DB)
 }
 
+// SetStmtCacheSize enables the cache of prepared statements. The driver keeps
+// up to size least recently used statements prepared and reuses them for the
+// queries with the same SQL text. The transactions rebind the cached
+// statements. If size <= 0, the cache is disabled.
+//
+// It should be called before the driver is used.
+func (d *Driver) SetStmtCacheSize(size int) {
+	conn := d.conn()
+
+	if conn.stmts != nil {
+		conn.stmts.close()
+		conn.stmts = nil
+	}
+
+	if size > 0 {
+		conn.stmts = newStmtCache(d.DB(), d.Dialect(), size)
+	}
+}
+
+func (d Driver) conn() *Conn {
+	return d.ExecQuerier.(*Conn)
+}
+
 // Dialect implements the dialect.Dialect method.
 func (d Driver) Dialect() string {
 	// If the underlying driver is wrapped with opencensus driver.
-	for _, name := range []string{dialect.MySQL, dialect.SQLite, dialect.Postgres} {
-		if strings.HasPrefix(d.name, name) {
-			return name
-		}
+	if spec, ok := dialect.Lookup(d.name); ok {
+		return spec.Name()
 	}
 	return d.name
 }
@@ -74,21 +97,71 @@ func (d *Driver) Tx(ctx context.Context) (dialect.Tx, error) {
 
 // BeginTx starts a transaction with options.
 func (d *Driver) BeginTx(ctx context.Context, opts *TxOptions) (dialect.Tx, error) {
-	tx, err := d.DB().BeginTx(ctx, opts)
+	if opts != nil && opts.Begin != "" {
+		return d.beginConn(ctx, opts)
+	}
+
+	var options *sql.TxOptions
+
+	if opts != nil {
+		options = &sql.TxOptions{
+			Isolation: opts.Isolation,
+			ReadOnly:  opts.ReadOnly,
+		}
+	}
+
+	tx, err := d.DB().BeginTx(ctx, options)
 	if err != nil {
 		return nil, err
 	}
 
 	dtx := &Tx{
-		ExecQuerier: &Conn{tx},
+		ExecQuerier: &Conn{ExecQuerier: tx, stmts: d.conn().stmts},
 		Tx:          tx,
 	}
 
-	return dtx, nil
+	return dtx.setup(ctx, opts)
+}
+
+// beginConn starts a transaction on a dedicated connection with the begin
+// statement provided by the options.
+func (d *Driver) beginConn(ctx context.Context, opts *TxOptions) (dialect.Tx, error) {
+	if opts.Isolation != sql.LevelDefault || opts.ReadOnly {
+		return nil, fmt.Errorf("dialect/sql: isolation level and read-only mode cannot be combined with %q", opts.Begin)
+	}
+
+	conn, err := d.DB().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, opts.Begin); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	dtx := &Tx{
+		ExecQuerier: &Conn{ExecQuerier: conn},
+		Tx:          &connTx{conn},
+	}
+
+	return dtx.setup(ctx, opts)
 }
 
-// Close closes the underlying connection.
-func (d *Driver) Close() error { return d.DB().Close() }
+// Close closes the cached statements and the underlying connection.
+func (d *Driver) Close() error {
+	var err error
+
+	if stmts := d.conn().stmts; stmts != nil {
+		err = stmts.close()
+	}
+
+	if cerr := d.DB().Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
 
 // Tx implements dialect.Tx interface.
 type Tx struct {
@@ -98,6 +171,53 @@ type Tx struct {
 	driver.Tx
 }
 
+// setup executes the statements provided by the options. The transaction is
+// rolled back if any of them fails.
+func (tx *Tx) setup(ctx context.Context, opts *TxOptions) (dialect.Tx, error) {
+	if opts == nil {
+		return tx, nil
+	}
+
+	for _, query := range opts.Statements {
+		if err := tx.Exec(ctx, query, []interface{}{}, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	return tx, nil
+}
+
+// connTx is a transaction started manually on a dedicated connection.
+type connTx struct {
+	conn *sql.Conn
+}
+
+// Commit commits the transaction and releases the connection.
+func (tx *connTx) Commit() error {
+	return tx.end("COMMIT")
+}
+
+// Rollback rollbacks the transaction and releases the connection.
+func (tx *connTx) Rollback() error {
+	return tx.end("ROLLBACK")
+}
+
+func (tx *connTx) end(query string) error {
+	if tx.conn == nil {
+		return sql.ErrTxDone
+	}
+
+	_, err := tx.conn.ExecContext(context.Background(), query)
+	// release the connection
+	if cerr := tx.conn.Close(); err == nil {
+		err = cerr
+	}
+
+	tx.conn = nil
+	return err
+}
+
 // ExecQuerier wraps the standard Exec and Query methods.
 type ExecQuerier interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
@@ -107,6 +227,8 @@ type ExecQuerier interface {
 // Conn implements dialect.ExecQuerier given ExecQuerier.
 type Conn struct {
 	ExecQuerier
+	// the cache of prepared statements (if enabled)
+	stmts *stmtCache
 }
 
 // Exec implements the dialect.Exec method.
@@ -115,13 +237,15 @@ func (c *Conn) Exec(ctx context.Context, query string, args, v interface{}) erro
 	if !ok {
 		return fmt.Errorf("dialect/sql: invalid type %T. expect []interface{} for args", v)
 	}
+	// the sensitive values are masked only in the logs
+	argv, _ = dialect.Reveal(argv)
 	switch v := v.(type) {
 	case nil:
-		if _, err := c.ExecContext(ctx, query, argv...); err != nil {
+		if _, err := c.execContext(ctx, query, argv...); err != nil {
 			return err
 		}
 	case *sql.Result:
-		res, err := c.ExecContext(ctx, query, argv...)
+		res, err := c.execContext(ctx, query, argv...)
 		if err != nil {
 			return err
 		}
@@ -142,7 +266,9 @@ func (c *Conn) Query(ctx context.Context, query string, args, v interface{}) err
 	if !ok {
 		return fmt.Errorf("dialect/sql: invalid type %T. expect []interface{} for args", args)
 	}
-	rows, err := c.QueryContext(ctx, query, argv...)
+	// the sensitive values are masked only in the logs
+	argv, _ = dialect.Reveal(argv)
+	rows, err := c.queryContext(ctx, query, argv...)
 	if err != nil {
 		return err
 	}
@@ -150,6 +276,52 @@ func (c *Conn) Query(ctx context.Context, query string, args, v interface{}) err
 	return nil
 }
 
+func (c *Conn) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, release := c.stmt(ctx, query)
+	if stmt == nil {
+		return c.ExecContext(ctx, query, args...)
+	}
+	defer release()
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+func (c *Conn) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, release := c.stmt(ctx, query)
+	if stmt == nil {
+		return c.QueryContext(ctx, query, args...)
+	}
+	defer release()
+
+	return stmt.QueryContext(ctx, args...)
+}
+
+// stmt returns the cached prepared statement of the given query and a
+// function that releases it. It returns nil if the cache is disabled or the
+// query cannot be prepared. In such case the query is executed directly.
+func (c *Conn) stmt(ctx context.Context, query string) (*sql.Stmt, func()) {
+	if c.stmts == nil || !cacheable(query, c.stmts.dialect) {
+		return nil, nil
+	}
+
+	entry, err := c.stmts.acquire(ctx, query)
+	if err != nil {
+		// the direct execution reports the error if the query is invalid
+		return nil, nil
+	}
+
+	release := func() {
+		c.stmts.release(entry)
+	}
+
+	if tx, ok := c.ExecQuerier.(*sql.Tx); ok {
+		// the rebound statement is closed when the transaction ends
+		return tx.StmtContext(ctx, entry.stmt), release
+	}
+
+	return entry.stmt, release
+}
+
 type (
 	// Rows wraps the sql.Rows to avoid locks copy.
 	Rows struct{ ColumnScanner }
@@ -165,10 +337,47 @@ type (
 	NullFloat64 = sql.NullFloat64
 	// NullTime represents a time.Time that may be null.
 	NullTime = sql.NullTime
-	// TxOptions holds the transaction options to be used in DB.BeginTx.
-	TxOptions = sql.TxOptions
+	// TxOptions holds the transaction options to be used in Driver.BeginTx.
+	TxOptions = dialect.TxOptions
 )
 
+// Observe registers a function that is called with the number of rows read
+// when the rows are closed. It implements the dialect.RowsObserver interface.
+func (r *Rows) Observe(fn func(count int64)) {
+	r.ColumnScanner = &observer{
+		ColumnScanner: r.ColumnScanner,
+		observe:       fn,
+	}
+}
+
+// observer is a ColumnScanner that counts the rows read.
+type observer struct {
+	ColumnScanner
+	count   int64
+	observe func(int64)
+}
+
+// Next prepares the next result row for reading.
+func (r *observer) Next() bool {
+	if r.ColumnScanner.Next() {
+		r.count++
+		return true
+	}
+	return false
+}
+
+// Close closes the rows and reports the number of rows read.
+func (r *observer) Close() error {
+	err := r.ColumnScanner.Close()
+
+	if r.observe != nil {
+		r.observe(r.count)
+		r.observe = nil
+	}
+
+	return err
+}
+
 // NullScanner represents an sql.Scanner that may be null.
 // NullScanner implements the sql.Scanner interface so it can
 // be used as a scan destination, similar to the types above.
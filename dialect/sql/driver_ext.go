@@ -4,10 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"io/fs"
-
-	"github.com/jmoiron/sqlx"
-	"github.com/phogolabs/prana/sqlexec"
-	"github.com/phogolabs/prana/sqlmigr"
 )
 
 // ErrNoRows is returned by Scan when QueryRow doesn't return a
@@ -18,17 +14,18 @@ var ErrNoRows = sql.ErrNoRows
 // FileSystem represents the SQL filesystem
 type FileSystem = fs.FS
 
-// Provider represents a routine provider
-type Provider = sqlexec.Provider
-
-// Migrate runs the migrations
-func (d Driver) Migrate(storage FileSystem) error {
-	db := sqlx.NewDb(d.DB(), d.name)
-	// execute the migration
-	return sqlmigr.RunAll(db, storage)
-}
-
 // Ping pings the server
 func (d Driver) Ping(ctx context.Context) error {
 	return d.DB().PingContext(ctx)
 }
+
+// Validate prepares the query to check that it's valid and closes the
+// statement.
+func (d Driver) Validate(ctx context.Context, query string) error {
+	stmt, err := d.DB().PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return stmt.Close()
+}
@@ -0,0 +1,104 @@
+package sql_test
+
+import (
+	"context"
+
+	"github.com/phogolabs/orm/dialect/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Driver", func() {
+	var (
+		ctx    context.Context
+		driver *sql.Driver
+	)
+
+	count := func(querier interface {
+		Query(context.Context, string, interface{}, interface{}) error
+	}, query string, args ...interface{}) int {
+		var (
+			rows  = &sql.Rows{}
+			value int
+		)
+
+		Expect(querier.Query(ctx, query, args, rows)).To(Succeed())
+		Expect(rows.Next()).To(BeTrue())
+		Expect(rows.Scan(&value)).To(Succeed())
+		Expect(rows.Close()).To(Succeed())
+		return value
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		ctx = context.TODO()
+
+		driver, err = sql.Open("sqlite3", "file:driver.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		driver.SetStmtCacheSize(2)
+
+		Expect(driver.Exec(ctx, "CREATE TABLE users (id int, name text)", []interface{}{}, nil)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(driver.Exec(ctx, "DROP TABLE users", []interface{}{}, nil)).To(Succeed())
+		Expect(driver.Close()).To(Succeed())
+	})
+
+	Describe("SetStmtCacheSize", func() {
+		It("reuses the prepared statements", func() {
+			var result sql.Result
+
+			for i := 0; i < 5; i++ {
+				Expect(driver.Exec(ctx, "INSERT INTO users VALUES (?, ?)", []interface{}{i, "user"}, &result)).To(Succeed())
+				Expect(result.RowsAffected()).To(BeEquivalentTo(1))
+				// the cache evicts the least recently used statements
+				Expect(count(driver, "SELECT COUNT(*) FROM users")).To(Equal(i + 1))
+				Expect(count(driver, "SELECT COUNT(*) FROM users WHERE id = ?", i)).To(Equal(1))
+				Expect(count(driver, "SELECT COUNT(*) FROM users WHERE id > ?", i)).To(Equal(0))
+			}
+		})
+
+		It("executes the scripts directly", func() {
+			query := "INSERT INTO users VALUES (1, 'a; b'); INSERT INTO users VALUES (2, 'c')"
+			Expect(driver.Exec(ctx, query, []interface{}{}, nil)).To(Succeed())
+			Expect(count(driver, "SELECT COUNT(*) FROM users")).To(Equal(2))
+		})
+
+		It("executes the scripts with quoted terminators directly", func() {
+			query := "INSERT INTO users VALUES (1, 'it''s; b') /* it's */; -- don't\nINSERT INTO users VALUES (2, 'c;')"
+			Expect(driver.Exec(ctx, query, []interface{}{}, nil)).To(Succeed())
+			Expect(count(driver, "SELECT COUNT(*) FROM users WHERE name = 'it''s; b' OR name = 'c;'")).To(Equal(2))
+		})
+
+		It("supports the named arguments", func() {
+			args := []interface{}{sql.NamedArg{Name: "id", Value: 1}, sql.NamedArg{Name: "name", Value: "root"}}
+			Expect(driver.Exec(ctx, "INSERT INTO users VALUES (:id, :name)", args, nil)).To(Succeed())
+			Expect(count(driver, "SELECT COUNT(*) FROM users WHERE name = :name", args[1])).To(Equal(1))
+		})
+
+		It("rebinds the statements in a transaction", func() {
+			Expect(count(driver, "SELECT COUNT(*) FROM users")).To(Equal(0))
+
+			tx, err := driver.Tx(ctx)
+			Expect(err).To(Succeed())
+
+			Expect(tx.Exec(ctx, "INSERT INTO users VALUES (?, ?)", []interface{}{1, "user"}, nil)).To(Succeed())
+			Expect(count(tx, "SELECT COUNT(*) FROM users")).To(Equal(1))
+			Expect(tx.Rollback()).To(Succeed())
+
+			Expect(count(driver, "SELECT COUNT(*) FROM users")).To(Equal(0))
+		})
+
+		Context("when the query is invalid", func() {
+			It("returns an error", func() {
+				err := driver.Query(ctx, "SELECT * FROM unknown", []interface{}{}, &sql.Rows{})
+				Expect(err).To(MatchError("no such table: unknown"))
+			})
+		})
+	})
+})
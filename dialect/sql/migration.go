@@ -0,0 +1,537 @@
+package sql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/prana/sqlexec"
+	"github.com/phogolabs/prana/sqlmigr"
+)
+
+// the table that keeps the checksums of the applied migration files
+const migrationChecksumTable = "migrations_checksum"
+
+// migrationTableRgxp matches the table created by the setup migration. The
+// applied migrations are recorded in it like in prana.
+var migrationTableRgxp = regexp.MustCompile(`CREATE TABLE IF NOT EXISTS\s*([a-zA-Z0-9\.]+)\s*`)
+
+type (
+	// Migration represents a migration of the database schema.
+	Migration = dialect.Migration
+	// MigrateOptions holds the options to be used in Driver.MigrateTo and
+	// Driver.Rollback.
+	MigrateOptions = dialect.MigrateOptions
+)
+
+// ChecksumError is returned when a migration file was changed after the
+// migration was applied.
+type ChecksumError struct {
+	// Filename is the name of the migration file.
+	Filename string
+	// Expected is the checksum of the file when the migration was applied.
+	Expected string
+	// Actual is the checksum of the current file.
+	Actual string
+}
+
+// Error implements the error interface.
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("dialect/sql: migration file %q was changed after it was applied (checksum %s, expected %s)", e.Filename, e.Actual, e.Expected)
+}
+
+// Migrate runs all pending migrations. The migrations are run under a lock
+// and the checksums of the applied migration files are verified.
+func (d Driver) Migrate(storage FileSystem) error {
+	m := d.migrator(storage, nil)
+
+	_, err := m.do(func(items []*sqlmigr.Migration) ([]*Migration, error) {
+		return m.up(items, len(items)-1)
+	})
+
+	return err
+}
+
+// MigrateTo runs the pending migrations up to the given version and reverts
+// the applied migrations newer than it. It returns the migrations that were
+// run or reverted with their statements.
+func (d Driver) MigrateTo(storage FileSystem, version string, opts *MigrateOptions) ([]*Migration, error) {
+	m := d.migrator(storage, opts)
+
+	return m.do(func(items []*sqlmigr.Migration) ([]*Migration, error) {
+		index := -1
+		for i, item := range items {
+			if item.ID == version {
+				index = i
+			}
+		}
+
+		if index < 0 {
+			return nil, fmt.Errorf("dialect/sql: migration version %q not found", version)
+		}
+
+		result, err := m.down(items, index, -1)
+		if err != nil {
+			return result, err
+		}
+
+		entries, err := m.up(items, index)
+		return append(result, entries...), err
+	})
+}
+
+// Rollback reverts the given number of applied migrations, starting from the
+// latest. If the steps are negative, it reverts all applied migrations.
+func (d Driver) Rollback(storage FileSystem, steps int, opts *MigrateOptions) ([]*Migration, error) {
+	m := d.migrator(storage, opts)
+
+	return m.do(func(items []*sqlmigr.Migration) ([]*Migration, error) {
+		return m.down(items, -1, steps)
+	})
+}
+
+// MigrationStatus returns all applied and pending migrations ordered by their
+// version.
+func (d Driver) MigrationStatus(storage FileSystem) ([]*Migration, error) {
+	m := d.migrator(storage, nil)
+
+	items, err := m.provider.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	checksums, err := m.checksums()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Migration, len(items))
+
+	for i, item := range items {
+		entry := m.entry(item, nil)
+
+		if !entry.Pending() {
+			for _, filename := range item.Filenames() {
+				checksum, err := m.checksum(filename)
+				if err != nil {
+					return nil, err
+				}
+
+				if expected, ok := checksums[filename]; ok && expected != checksum {
+					entry.Modified = true
+				}
+			}
+		}
+
+		result[i] = entry
+	}
+
+	return result, nil
+}
+
+func (d Driver) migrator(storage FileSystem, opts *MigrateOptions) *migrator {
+	if opts == nil {
+		opts = &MigrateOptions{}
+	}
+
+	db := sqlx.NewDb(d.DB(), d.name)
+
+	return &migrator{
+		db:      db,
+		dialect: d.Dialect(),
+		storage: storage,
+		options: opts,
+		provider: &sqlmigr.Provider{
+			FileSystem: storage,
+			DB:         db,
+		},
+	}
+}
+
+// migrator runs and reverts the migrations one by one.
+type migrator struct {
+	db       *sqlx.DB
+	table    string
+	dialect  string
+	storage  FileSystem
+	options  *MigrateOptions
+	provider *sqlmigr.Provider
+}
+
+// do executes the given operation under the migration lock after the
+// checksums of the applied migrations are verified. The lock is not taken in
+// dry-run mode.
+func (m *migrator) do(fn func([]*sqlmigr.Migration) ([]*Migration, error)) (result []*Migration, err error) {
+	if !m.options.DryRun {
+		unlock, err := m.lock()
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() {
+			if uerr := unlock(); err == nil {
+				err = uerr
+			}
+		}()
+
+		query := "CREATE TABLE IF NOT EXISTS " + migrationChecksumTable + " ("
+		query += "filename VARCHAR(255) NOT NULL PRIMARY KEY, "
+		query += "checksum VARCHAR(64) NOT NULL)"
+
+		if _, err := m.db.Exec(query); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := m.provider.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.verify(items); err != nil {
+		return nil, err
+	}
+
+	m.table = m.tableOf(items)
+
+	return fn(items)
+}
+
+// up runs the pending migrations up to the given index.
+func (m *migrator) up(items []*sqlmigr.Migration, index int) ([]*Migration, error) {
+	result := []*Migration{}
+
+	for i := 0; i <= index; i++ {
+		if !items[i].CreatedAt.IsZero() {
+			continue
+		}
+
+		entry, err := m.run(items[i])
+		if err != nil {
+			return result, err
+		}
+
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+// down reverts the given number of applied migrations after the given index.
+func (m *migrator) down(items []*sqlmigr.Migration, index, steps int) ([]*Migration, error) {
+	result := []*Migration{}
+
+	for i := len(items) - 1; i > index && steps != 0; i-- {
+		if items[i].CreatedAt.IsZero() {
+			continue
+		}
+
+		entry, err := m.revert(items[i])
+		if err != nil {
+			return result, err
+		}
+
+		result = append(result, entry)
+		steps--
+	}
+
+	return result, nil
+}
+
+func (m *migrator) run(item *sqlmigr.Migration) (*Migration, error) {
+	statements, err := m.script("up", item)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.options.DryRun {
+		checksums := make(map[string]string)
+
+		for _, filename := range item.Filenames() {
+			checksum, err := m.checksum(filename)
+			if err != nil {
+				return nil, err
+			}
+
+			checksums[filename] = checksum
+		}
+
+		createdAt := time.Now()
+
+		// the migration is recorded with its checksums in the same
+		// transaction, so it's never applied without them
+		err := m.exec(statements, func(tx *sqlx.Tx) error {
+			query := m.db.Rebind("INSERT INTO " + m.table + " (id, description, created_at) VALUES (?, ?, ?)")
+
+			if _, err := tx.Exec(query, item.ID, item.Description, createdAt); err != nil {
+				return err
+			}
+
+			for filename, checksum := range checksums {
+				if err := m.record(tx, filename, checksum); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		item.CreatedAt = createdAt
+	}
+
+	return m.entry(item, statements), nil
+}
+
+func (m *migrator) revert(item *sqlmigr.Migration) (*Migration, error) {
+	statements, err := m.script("down", item)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.options.DryRun {
+		if err := m.exec(statements, nil); err != nil {
+			return nil, err
+		}
+
+		// the setup migration drops the migrations table
+		if err := m.provider.Delete(item); err != nil && !sqlmigr.IsNotExist(err) {
+			return nil, err
+		}
+
+		for _, filename := range item.Filenames() {
+			if err := m.record(m.db, filename, ""); err != nil {
+				return nil, err
+			}
+		}
+
+		item.CreatedAt = time.Time{}
+	}
+
+	return m.entry(item, statements), nil
+}
+
+// exec executes the statements in a transaction. The given function (if any)
+// is called in the same transaction after the statements.
+func (m *migrator) exec(statements []string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, query := range statements {
+		if _, err := tx.Exec(query); err != nil {
+			// the statement error is more important
+			_ = tx.Rollback()
+
+			return &sqlmigr.RunnerError{
+				Err:       err,
+				Statement: query,
+			}
+		}
+	}
+
+	if fn != nil {
+		if err := fn(tx); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// verify compares the checksums of the applied migration files with the
+// recorded ones. The missing checksums of the migrations applied before the
+// checksums were introduced are recorded. The changed checksums are updated
+// only in repair mode.
+func (m *migrator) verify(items []*sqlmigr.Migration) error {
+	checksums, err := m.checksums()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.CreatedAt.IsZero() {
+			continue
+		}
+
+		for _, filename := range item.Filenames() {
+			checksum, err := m.checksum(filename)
+			if err != nil {
+				return err
+			}
+
+			expected, ok := checksums[filename]
+
+			switch {
+			case ok && expected == checksum:
+				continue
+			case ok && !m.options.Repair:
+				return &ChecksumError{
+					Filename: filename,
+					Expected: expected,
+					Actual:   checksum,
+				}
+			}
+
+			if m.options.DryRun {
+				continue
+			}
+
+			if err := m.record(m.db, filename, checksum); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checksums returns the recorded checksums of the applied migration files.
+func (m *migrator) checksums() (map[string]string, error) {
+	rows := []struct {
+		Filename string `db:"filename"`
+		Checksum string `db:"checksum"`
+	}{}
+
+	query := "SELECT filename, checksum FROM " + migrationChecksumTable
+
+	if err := m.db.Select(&rows, query); err != nil && !sqlmigr.IsNotExist(err) {
+		return nil, err
+	}
+
+	checksums := make(map[string]string, len(rows))
+
+	for _, row := range rows {
+		checksums[row.Filename] = row.Checksum
+	}
+
+	return checksums, nil
+}
+
+// record saves the checksum of the migration file. An empty checksum deletes
+// the record.
+func (m *migrator) record(db sqlx.Execer, filename, checksum string) error {
+	query := m.db.Rebind("DELETE FROM " + migrationChecksumTable + " WHERE filename = ?")
+
+	if _, err := db.Exec(query, filename); err != nil {
+		return err
+	}
+
+	if checksum == "" {
+		return nil
+	}
+
+	query = m.db.Rebind("INSERT INTO " + migrationChecksumTable + " (filename, checksum) VALUES (?, ?)")
+
+	_, err := db.Exec(query, filename, checksum)
+	return err
+}
+
+// tableOf returns the name of the migrations table. It's the table created by
+// the setup migration.
+func (m *migrator) tableOf(items []*sqlmigr.Migration) string {
+	for _, item := range items {
+		if item.Description != "setup" {
+			continue
+		}
+
+		for _, filename := range item.Filenames() {
+			data, err := m.read(filename)
+			if err != nil {
+				continue
+			}
+
+			if match := migrationTableRgxp.FindSubmatch(data); len(match) == 2 {
+				return string(match[1])
+			}
+		}
+	}
+
+	return "migrations"
+}
+
+// checksum returns the SHA-256 checksum of the migration file.
+func (m *migrator) checksum(filename string) (string, error) {
+	data, err := m.read(filename)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// script returns the statements of the given routine (up or down) of the
+// migration.
+func (m *migrator) script(name string, item *sqlmigr.Migration) ([]string, error) {
+	filenames := item.Filenames()
+
+	if name == "down" {
+		for i, j := 0, len(filenames)-1; i < j; i, j = i+1, j-1 {
+			filenames[i], filenames[j] = filenames[j], filenames[i]
+		}
+	}
+
+	var (
+		routines   []string
+		scanner    = &sqlexec.Scanner{}
+		splitter   = &sqlexec.Splitter{}
+		statements = []string{}
+	)
+
+	for _, filename := range filenames {
+		data, err := m.read(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if body, ok := scanner.Scan(bytes.NewReader(data))[name]; ok {
+			routines = append(routines, body)
+		}
+	}
+
+	if len(routines) == 0 {
+		return nil, fmt.Errorf("routine '%s' not found for migration '%v'", name, item)
+	}
+
+	for _, body := range routines {
+		for _, query := range splitter.Split(bytes.NewBufferString(body)) {
+			if query = strings.TrimSpace(query); query != "" {
+				statements = append(statements, query)
+			}
+		}
+	}
+
+	return statements, nil
+}
+
+func (m *migrator) read(filename string) ([]byte, error) {
+	file, err := m.storage.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	// close the file
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+func (m *migrator) entry(item *sqlmigr.Migration, statements []string) *Migration {
+	return &Migration{
+		ID:          item.ID,
+		Description: item.Description,
+		AppliedAt:   item.CreatedAt,
+		Statements:  statements,
+	}
+}
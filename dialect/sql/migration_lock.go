@@ -0,0 +1,170 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/phogolabs/orm/dialect"
+)
+
+const (
+	// the name of the MySQL lock
+	migrationLockName = "orm_migrations"
+	// the key of the PostgreSQL advisory lock ("orm_migr" in ASCII)
+	migrationLockKey = 0x6f726d5f6d696772
+	// the table that keeps the SQLite lock row
+	migrationLockTable = "migrations_lock"
+	// the default time to wait for the lock
+	migrationLockTimeout = time.Minute
+	// the age after which the SQLite lock row is taken over
+	migrationLockExpiration = 10 * time.Minute
+	// the interval between the attempts to insert the SQLite lock row
+	migrationLockInterval = 100 * time.Millisecond
+)
+
+// lock acquires a cross-process lock that guards the migrations. It's an
+// advisory lock in PostgreSQL, a named lock in MySQL and a lock row in
+// SQLite. It returns a function that releases the lock.
+func (m *migrator) lock() (func() error, error) {
+	timeout := m.options.LockTimeout
+	if timeout <= 0 {
+		timeout = migrationLockTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var (
+		unlock func() error
+		err    error
+	)
+
+	switch dialect.FamilyOf(m.dialect) {
+	case dialect.Postgres:
+		unlock, err = m.lockPostgres(ctx)
+	case dialect.MySQL:
+		unlock, err = m.lockMySQL(ctx, timeout)
+	case dialect.SQLite:
+		unlock, err = m.lockSQLite(ctx, timeout)
+	default:
+		unlock = func() error { return nil }
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("dialect/sql: cannot acquire the migration lock: %w", err)
+	}
+
+	return unlock, nil
+}
+
+func (m *migrator) lockPostgres(ctx context.Context) (func() error, error) {
+	// the advisory lock is held by the session
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	unlock := func() error {
+		// close the connection
+		defer conn.Close()
+
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		return err
+	}
+
+	return unlock, nil
+}
+
+func (m *migrator) lockMySQL(ctx context.Context, timeout time.Duration) (func() error, error) {
+	// the named lock is held by the session
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired sql.NullInt64
+
+	// GET_LOCK waits for the lock up to the given number of seconds
+	row := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, int(timeout.Seconds()))
+
+	if err := row.Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if acquired.Int64 != 1 {
+		conn.Close()
+		return nil, fmt.Errorf("lock %q timed out", migrationLockName)
+	}
+
+	unlock := func() error {
+		// close the connection
+		defer conn.Close()
+
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+		return err
+	}
+
+	return unlock, nil
+}
+
+// lockSQLite inserts the lock row. The row older than the lock expiration is
+// taken over, because the process that inserted it has crashed.
+func (m *migrator) lockSQLite(ctx context.Context, timeout time.Duration) (func() error, error) {
+	expiration := m.options.LockExpiration
+	if expiration <= 0 {
+		expiration = migrationLockExpiration
+	}
+
+	query := "CREATE TABLE IF NOT EXISTS " + migrationLockTable + " ("
+	query += "id INTEGER NOT NULL PRIMARY KEY, "
+	query += "locked_at TIMESTAMP NOT NULL)"
+
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return nil, err
+	}
+
+	// the primary key allows only one lock row
+	query = "INSERT INTO " + migrationLockTable + " (id, locked_at) VALUES (1, ?) "
+	query += "ON CONFLICT (id) DO UPDATE SET locked_at = excluded.locked_at "
+	query += "WHERE " + migrationLockTable + ".locked_at < ?"
+
+	var lockedAt time.Time
+
+	for {
+		lockedAt = time.Now().UTC()
+
+		result, err := m.db.ExecContext(ctx, query, lockedAt, lockedAt.Add(-expiration))
+		if err != nil {
+			return nil, err
+		}
+
+		// the row is not changed if it's held by another process
+		if count, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if count > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("lock %q timed out after %v", migrationLockTable, timeout)
+		case <-time.After(migrationLockInterval):
+		}
+	}
+
+	unlock := func() error {
+		// the row may be taken over by another process
+		_, err := m.db.Exec("DELETE FROM "+migrationLockTable+" WHERE id = 1 AND locked_at = ?", lockedAt)
+		return err
+	}
+
+	return unlock, nil
+}
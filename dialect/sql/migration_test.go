@@ -0,0 +1,292 @@
+package sql_test
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/phogolabs/orm/dialect/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration", func() {
+	var (
+		driver  *sql.Driver
+		storage fstest.MapFS
+	)
+
+	migration := func(up, down string) *fstest.MapFile {
+		return &fstest.MapFile{
+			Data: []byte("-- name: up\n" + up + "\n\n-- name: down\n" + down + "\n"),
+		}
+	}
+
+	status := func() []string {
+		items, err := driver.MigrationStatus(storage)
+		Expect(err).To(BeNil())
+
+		result := []string{}
+		for _, item := range items {
+			if item.Pending() {
+				result = append(result, item.ID+" pending")
+			} else {
+				result = append(result, item.ID+" applied")
+			}
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		driver, err = sql.Open("sqlite3", "file:migration.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		storage = fstest.MapFS{
+			"00060524000000_setup.sql": migration(
+				"CREATE TABLE IF NOT EXISTS migrations (id TEXT NOT NULL PRIMARY KEY, description TEXT NOT NULL, created_at TIMESTAMP NOT NULL);",
+				"DROP TABLE IF EXISTS migrations;",
+			),
+			"20180406190015_users.sql": migration(
+				"CREATE TABLE users (id INT PRIMARY KEY NOT NULL, name TEXT);",
+				"DROP TABLE IF EXISTS users;",
+			),
+			"20180406190016_groups.sql": migration(
+				"CREATE TABLE groups (id INT PRIMARY KEY NOT NULL);\nGO\nCREATE INDEX groups_id ON groups (id);",
+				"DROP TABLE IF EXISTS groups;",
+			),
+		}
+	})
+
+	AfterEach(func() {
+		_, err := driver.Rollback(storage, -1, &sql.MigrateOptions{Repair: true})
+		Expect(err).To(BeNil())
+		Expect(driver.Close()).To(Succeed())
+	})
+
+	Describe("MigrationStatus", func() {
+		It("returns the pending migrations", func() {
+			Expect(status()).To(Equal([]string{
+				"00060524000000 pending",
+				"20180406190015 pending",
+				"20180406190016 pending",
+			}))
+		})
+
+		It("returns the applied migrations with their timestamps", func() {
+			Expect(driver.Migrate(storage)).To(Succeed())
+
+			items, err := driver.MigrationStatus(storage)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(3))
+
+			for _, item := range items {
+				Expect(item.Pending()).To(BeFalse())
+				Expect(item.AppliedAt).NotTo(BeZero())
+			}
+
+			Expect(items[1].String()).To(Equal("20180406190015_users"))
+		})
+	})
+
+	Describe("MigrateTo", func() {
+		It("runs the migrations up to the version", func() {
+			items, err := driver.MigrateTo(storage, "20180406190015", nil)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(2))
+			Expect(items[1].Description).To(Equal("users"))
+			Expect(items[1].AppliedAt).NotTo(BeZero())
+			Expect(items[1].Statements).To(HaveLen(1))
+
+			Expect(status()).To(Equal([]string{
+				"00060524000000 applied",
+				"20180406190015 applied",
+				"20180406190016 pending",
+			}))
+		})
+
+		It("reverts the migrations newer than the version", func() {
+			Expect(driver.Migrate(storage)).To(Succeed())
+
+			items, err := driver.MigrateTo(storage, "00060524000000", nil)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(2))
+			Expect(items[0].ID).To(Equal("20180406190016"))
+			Expect(items[0].Pending()).To(BeTrue())
+			Expect(items[1].ID).To(Equal("20180406190015"))
+
+			Expect(status()).To(Equal([]string{
+				"00060524000000 applied",
+				"20180406190015 pending",
+				"20180406190016 pending",
+			}))
+		})
+
+		Context("when the dry-run mode is enabled", func() {
+			It("returns the statements without running them", func() {
+				items, err := driver.MigrateTo(storage, "20180406190016", &sql.MigrateOptions{DryRun: true})
+				Expect(err).To(BeNil())
+				Expect(items).To(HaveLen(3))
+				Expect(items[2].Statements).To(Equal([]string{
+					"CREATE TABLE groups (id INT PRIMARY KEY NOT NULL);",
+					"CREATE INDEX groups_id ON groups (id);",
+				}))
+
+				Expect(status()).To(Equal([]string{
+					"00060524000000 pending",
+					"20180406190015 pending",
+					"20180406190016 pending",
+				}))
+			})
+		})
+
+		Context("when the version does not exist", func() {
+			It("returns an error", func() {
+				items, err := driver.MigrateTo(storage, "20180406190017", nil)
+				Expect(err).To(MatchError(`dialect/sql: migration version "20180406190017" not found`))
+				Expect(items).To(BeEmpty())
+			})
+		})
+
+		Context("when a statement fails", func() {
+			It("returns an error", func() {
+				storage["20180406190016_groups.sql"] = migration("CREATE TABLE unknown.groups (id INT);", "")
+
+				items, err := driver.MigrateTo(storage, "20180406190016", nil)
+				Expect(err).To(MatchError("unknown database unknown: CREATE TABLE unknown.groups (id INT);"))
+				Expect(items).To(HaveLen(2))
+
+				Expect(status()).To(Equal([]string{
+					"00060524000000 applied",
+					"20180406190015 applied",
+					"20180406190016 pending",
+				}))
+			})
+		})
+
+		Context("when the checksum cannot be recorded", func() {
+			It("does not apply the migration", func() {
+				storage["20180406190016_groups.sql"] = migration("DROP TABLE migrations_checksum;", "")
+
+				items, err := driver.MigrateTo(storage, "20180406190016", nil)
+				Expect(err).To(MatchError("no such table: migrations_checksum"))
+				Expect(items).To(HaveLen(2))
+
+				Expect(status()).To(Equal([]string{
+					"00060524000000 applied",
+					"20180406190015 applied",
+					"20180406190016 pending",
+				}))
+			})
+		})
+	})
+
+	Describe("Rollback", func() {
+		BeforeEach(func() {
+			Expect(driver.Migrate(storage)).To(Succeed())
+		})
+
+		It("reverts the latest migrations", func() {
+			items, err := driver.Rollback(storage, 1, nil)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(1))
+			Expect(items[0].ID).To(Equal("20180406190016"))
+			Expect(items[0].Statements).To(Equal([]string{"DROP TABLE IF EXISTS groups;"}))
+
+			Expect(status()).To(Equal([]string{
+				"00060524000000 applied",
+				"20180406190015 applied",
+				"20180406190016 pending",
+			}))
+		})
+
+		It("reverts all migrations", func() {
+			items, err := driver.Rollback(storage, -1, nil)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(3))
+			Expect(status()).To(HaveEach(HaveSuffix("pending")))
+		})
+
+		Context("when the dry-run mode is enabled", func() {
+			It("returns the statements without running them", func() {
+				items, err := driver.Rollback(storage, 2, &sql.MigrateOptions{DryRun: true})
+				Expect(err).To(BeNil())
+				Expect(items).To(HaveLen(2))
+				Expect(items[1].Statements).To(Equal([]string{"DROP TABLE IF EXISTS users;"}))
+				Expect(status()).To(HaveEach(HaveSuffix("applied")))
+			})
+		})
+	})
+
+	Describe("Checksum", func() {
+		BeforeEach(func() {
+			Expect(driver.Migrate(storage)).To(Succeed())
+			storage["20180406190015_users.sql"] = migration("CREATE TABLE users (id INT);", "DROP TABLE users;")
+		})
+
+		It("reports the modified migrations", func() {
+			items, err := driver.MigrationStatus(storage)
+			Expect(err).To(BeNil())
+			Expect(items).To(HaveLen(3))
+			Expect(items[0].Modified).To(BeFalse())
+			Expect(items[1].Modified).To(BeTrue())
+			Expect(items[2].Modified).To(BeFalse())
+		})
+
+		It("refuses to run the migrations", func() {
+			Expect(driver.Migrate(storage)).To(MatchError(ContainSubstring(`migration file "20180406190015_users.sql" was changed after it was applied`)))
+
+			items, err := driver.Rollback(storage, 1, nil)
+			Expect(items).To(BeEmpty())
+			Expect(err).To(BeAssignableToTypeOf(&sql.ChecksumError{}))
+			Expect(status()).To(HaveEach(HaveSuffix("applied")))
+		})
+
+		Context("when the repair option is given", func() {
+			It("updates the checksums", func() {
+				items, err := driver.Rollback(storage, 1, &sql.MigrateOptions{Repair: true})
+				Expect(err).To(BeNil())
+				Expect(items).To(HaveLen(1))
+
+				Expect(driver.Migrate(storage)).To(Succeed())
+
+				items, err = driver.MigrationStatus(storage)
+				Expect(err).To(BeNil())
+				Expect(items[1].Modified).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Lock", func() {
+		It("waits for the lock", func() {
+			Expect(driver.Migrate(storage)).To(Succeed())
+			Expect(driver.Exec(context.TODO(), "INSERT INTO migrations_lock (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)", []interface{}{}, nil)).To(Succeed())
+
+			items, err := driver.Rollback(storage, 1, &sql.MigrateOptions{LockTimeout: 200 * time.Millisecond})
+			Expect(err).To(MatchError(`dialect/sql: cannot acquire the migration lock: lock "migrations_lock" timed out after 200ms`))
+			Expect(items).To(BeEmpty())
+
+			Expect(driver.Exec(context.TODO(), "DELETE FROM migrations_lock", []interface{}{}, nil)).To(Succeed())
+			Expect(status()).To(HaveEach(HaveSuffix("applied")))
+		})
+
+		Context("when the lock is expired", func() {
+			It("takes it over", func() {
+				Expect(driver.Migrate(storage)).To(Succeed())
+				Expect(driver.Exec(context.TODO(), "INSERT INTO migrations_lock (id, locked_at) VALUES (1, '2000-01-01 00:00:00')", []interface{}{}, nil)).To(Succeed())
+
+				items, err := driver.Rollback(storage, 1, &sql.MigrateOptions{LockTimeout: 200 * time.Millisecond})
+				Expect(err).To(BeNil())
+				Expect(items).To(HaveLen(1))
+
+				rows := &sql.Rows{}
+				Expect(driver.Query(context.TODO(), "SELECT id FROM migrations_lock", []interface{}{}, rows)).To(Succeed())
+				Expect(rows.Next()).To(BeFalse())
+				Expect(rows.Close()).To(Succeed())
+			})
+		})
+	})
+})
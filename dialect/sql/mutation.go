@@ -1,6 +1,7 @@
 package sql
 
 import (
+	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql/scan"
 )
 
@@ -36,6 +37,10 @@ func (d *InsertMutation) Entity(src interface{}) *InsertBuilder {
 			}
 		}
 
+		if column.HasOption("sensitive") {
+			value = dialect.Sensitive(value)
+		}
+
 		columns = append(columns, column.Name)
 		values = append(values, value)
 	}
@@ -77,6 +82,7 @@ func (d *UpdateMutation) Entity(src interface{}, columns ...string) *UpdateBuild
 		empty      = len(columns) == 0
 		iterator   = scan.IteratorOf(src)
 		updateable = make(map[string]interface{})
+		sensitive  = make(map[string]bool)
 	)
 
 	for iterator.Next() {
@@ -93,6 +99,7 @@ func (d *UpdateMutation) Entity(src interface{}, columns ...string) *UpdateBuild
 		// we can update only immutable columns
 		if !immutable {
 			updateable[column.Name] = value
+			sensitive[column.Name] = column.HasOption("sensitive")
 		}
 
 		if d.conflict {
@@ -107,9 +114,12 @@ func (d *UpdateMutation) Entity(src interface{}, columns ...string) *UpdateBuild
 
 	for _, name := range columns {
 		if value, ok := updateable[name]; ok {
-			if scan.IsNil(value) {
+			switch {
+			case scan.IsNil(value):
 				builder.SetNull(name)
-			} else {
+			case sensitive[name]:
+				builder.Set(name, dialect.Sensitive(value))
+			default:
 				builder.Set(name, value)
 			}
 		}
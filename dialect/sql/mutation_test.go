@@ -1,6 +1,9 @@
 package sql_test
 
 import (
+	"fmt"
+
+	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -36,6 +39,23 @@ var _ = Describe("Mutation", func() {
 			Expect(params[2]).To(Equal(entity.Email))
 			Expect(params[3]).To(Equal(entity.Group.ID))
 		})
+
+		Context("when the column is sensitive", func() {
+			type Account struct {
+				ID       string `db:"id,primary_key"`
+				Password string `db:"password,sensitive"`
+			}
+
+			It("masks the value", func() {
+				account := &Account{ID: "007", Password: "secret"}
+				query, params := sql.NewInsert("accounts").Entity(account).Query()
+
+				Expect(query).To(Equal("INSERT INTO `accounts` (`id`, `password`) VALUES (?, ?)"))
+				Expect(params).To(HaveLen(2))
+				Expect(params[1]).To(Equal(dialect.Sensitive("secret")))
+				Expect(fmt.Sprint(params[1])).To(Equal("[REDACTED]"))
+			})
+		})
 	})
 
 	Describe("UpdateMutation", func() {
@@ -0,0 +1,285 @@
+package sql
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/orm/dialect"
+)
+
+var (
+	routineName    = regexp.MustCompile(`^\s*--\s*name:\s*(\S+)`)
+	routineDialect = regexp.MustCompile(`^\s*--\s*dialect:\s*(\S+)`)
+)
+
+// Provider loads the routines from SQL files and provides their queries. A
+// routine can have a variant for each dialect. The variant is declared by
+// the name of the file (e.g. routine.postgres.sql or routine_postgres.sql)
+// or by a `-- dialect: postgres` annotation that follows the `-- name: x`
+// annotation of the routine. The routines without a dialect are used when
+// there is no variant for the dialect.
+type Provider struct {
+	dialect    string
+	mu         sync.RWMutex
+	repository map[string]map[string]string
+}
+
+// routineBlock is a routine read from a SQL file.
+type routineBlock struct {
+	name    string
+	dialect string
+	lines   []string
+}
+
+// Dialect returns the dialect
+func (p *Provider) Dialect() string {
+	return p.dialect
+}
+
+// SetDialect sets the dialect
+func (p *Provider) SetDialect(value string) {
+	p.dialect = value
+}
+
+// ReadDir loads the routines of all SQL files in the given directory.
+func (p *Provider) ReadDir(storage FileSystem) error {
+	return fs.WalkDir(storage, ".", func(path string, info fs.DirEntry, err error) error {
+		if info == nil {
+			return os.ErrNotExist
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		return p.ReadFile(path, storage)
+	})
+}
+
+// ReadFile loads the routines of the given SQL file. The routines are the
+// variants of the dialect that is declared by the name of the file.
+func (p *Provider) ReadFile(path string, storage FileSystem) error {
+	if filepath.Ext(path) != ".sql" {
+		return nil
+	}
+
+	file, err := storage.Open(path)
+	if err != nil {
+		return err
+	}
+	// close the file
+	defer file.Close()
+
+	_, err = p.read(file, dialectOf(path))
+	return err
+}
+
+// ReadFrom loads the routines from the given reader.
+func (p *Provider) ReadFrom(r io.Reader) (int64, error) {
+	return p.read(r, "")
+}
+
+// read loads the routines from the reader. The routines are the variants of
+// the given dialect unless they are annotated with another one.
+func (p *Provider) read(r io.Reader, dialectName string) (int64, error) {
+	var (
+		blocks  []*routineBlock
+		scanner = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := routineName.FindStringSubmatch(line); match != nil {
+			blocks = append(blocks, &routineBlock{name: match[1], dialect: dialectName})
+			continue
+		}
+
+		if len(blocks) == 0 {
+			continue
+		}
+
+		block := blocks[len(blocks)-1]
+
+		if match := routineDialect.FindStringSubmatch(line); match != nil {
+			block.dialect = strings.ToLower(match[1])
+			continue
+		}
+
+		if line = strings.Trim(line, " \t"); line != "" {
+			block.lines = append(block.lines, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	// the blocks of the same routine in a file are joined
+	routines := make(map[string]map[string]string)
+
+	for _, block := range blocks {
+		key := canonical(block.dialect)
+
+		if _, ok := routines[block.name]; !ok {
+			routines[block.name] = make(map[string]string)
+		}
+
+		if query := routines[block.name][key]; query != "" && len(block.lines) > 0 {
+			routines[block.name][key] = query + "\n"
+		}
+
+		routines[block.name][key] += strings.Join(block.lines, "\n")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.repository == nil {
+		p.repository = make(map[string]map[string]string)
+	}
+
+	var count int64
+
+	for name, variants := range routines {
+		for key := range variants {
+			if _, ok := p.repository[name][key]; !ok {
+				continue
+			}
+
+			if key == "" {
+				return 0, fmt.Errorf("query '%s' already exists", name)
+			}
+
+			return 0, fmt.Errorf("query '%s' already exists for dialect %s", name, key)
+		}
+	}
+
+	for name, variants := range routines {
+		if _, ok := p.repository[name]; !ok {
+			p.repository[name] = make(map[string]string)
+		}
+
+		for key, query := range variants {
+			p.repository[name][key] = query
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Names returns the sorted names of all routines.
+func (p *Provider) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.repository))
+
+	for name := range p.repository {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// NamesOf returns the sorted names of the routines that have a variant for
+// the given dialect, including the routines without a dialect.
+func (p *Provider) NamesOf(dialectName string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.repository))
+
+	for name, variants := range p.repository {
+		if _, ok := variantOf(variants, dialectName); ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Query returns the query of the routine for the dialect of the provider.
+func (p *Provider) Query(name string) (string, error) {
+	return p.QueryOf(name, p.dialect)
+}
+
+// QueryOf returns the query of the routine for the given dialect. It picks
+// the variant of the dialect, then the variant of its family (e.g. postgres
+// for CockroachDB) and finally the routine without a dialect.
+func (p *Provider) QueryOf(name, dialectName string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	variants, ok := p.repository[name]
+	if !ok {
+		return "", fmt.Errorf("query '%s' not found", name)
+	}
+
+	if query, ok := variantOf(variants, dialectName); ok {
+		return sqlx.Rebind(sqlx.BindType(dialectName), query), nil
+	}
+
+	return "", fmt.Errorf("query '%s' not found for dialect %s", name, dialectName)
+}
+
+// variantOf returns the variant of the dialect, then the variant of its
+// family and finally the variant without a dialect.
+func variantOf(variants map[string]string, dialectName string) (string, bool) {
+	keys := []string{
+		canonical(dialectName),
+		dialect.FamilyOf(dialectName),
+		"",
+	}
+
+	for _, key := range keys {
+		if query, ok := variants[key]; ok {
+			return query, true
+		}
+	}
+
+	return "", false
+}
+
+// dialectOf returns the dialect declared by the name of the file. The dialect
+// is the last part of the name separated by a dot or an underscore (e.g.
+// routine.postgres.sql or routine_postgres.sql). It must be registered for
+// exactly that name, so the suffixes like mysqlbackup are not dialects.
+func dialectOf(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	index := strings.LastIndexAny(name, "._")
+	if index < 0 {
+		return ""
+	}
+
+	if suffix := strings.ToLower(name[index+1:]); suffix != "" {
+		if dialect.Registered(suffix) {
+			return suffix
+		}
+	}
+
+	return ""
+}
+
+// canonical returns the name of the registered dialect of the given driver
+// name (e.g. postgres for pgx). The unknown names are returned as they are.
+func canonical(name string) string {
+	if spec, ok := dialect.Lookup(name); ok {
+		return spec.Name()
+	}
+
+	return name
+}
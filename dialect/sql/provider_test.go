@@ -0,0 +1,128 @@
+package sql_test
+
+import (
+	"strings"
+	"testing/fstest"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type CockroachDB struct {
+	dialect.Dialect
+}
+
+func (CockroachDB) Name() string {
+	return "cockroach"
+}
+
+var _ = Describe("Provider", func() {
+	var (
+		provider *sql.Provider
+		storage  fstest.MapFS
+	)
+
+	BeforeEach(func() {
+		dialect.Register("cockroach", &CockroachDB{dialect.MustLookup(dialect.Postgres)})
+
+		storage = fstest.MapFS{
+			"user.sql": &fstest.MapFile{
+				Data: []byte(strings.Join([]string{
+					"-- name: find-user",
+					"SELECT * FROM users",
+					"WHERE id = ?;",
+					"",
+					"-- name: list-users",
+					"-- dialect: mysql",
+					"SELECT * FROM `users`;",
+				}, "\n")),
+			},
+			"user.postgres.sql": &fstest.MapFile{
+				Data: []byte("-- name: find-user\nSELECT * FROM users WHERE id = ?::int;\n"),
+			},
+			"user_sqlite3.sql": &fstest.MapFile{
+				Data: []byte("-- name: list-users\nSELECT * FROM users;\n"),
+			},
+			"README.md": &fstest.MapFile{
+				Data: []byte("-- name: readme\n"),
+			},
+		}
+
+		provider = &sql.Provider{}
+		Expect(provider.ReadDir(storage)).To(Succeed())
+	})
+
+	It("returns the names of the routines", func() {
+		Expect(provider.Names()).To(Equal([]string{"find-user", "list-users"}))
+		Expect(provider.NamesOf(dialect.SQLite)).To(Equal([]string{"find-user", "list-users"}))
+		Expect(provider.NamesOf(dialect.Postgres)).To(Equal([]string{"find-user"}))
+	})
+
+	DescribeTable("returns the variant of the dialect",
+		func(name, dialectName, query string) {
+			value, err := provider.QueryOf(name, dialectName)
+			Expect(err).To(BeNil())
+			Expect(value).To(Equal(query))
+		},
+		Entry("file suffix", "find-user", dialect.Postgres, "SELECT * FROM users WHERE id = $1::int;"),
+		Entry("driver name", "find-user", "pgx", "SELECT * FROM users WHERE id = $1::int;"),
+		Entry("family", "find-user", "cockroach", "SELECT * FROM users WHERE id = $1::int;"),
+		Entry("fallback", "find-user", dialect.MySQL, "SELECT * FROM users\nWHERE id = ?;"),
+		Entry("annotation", "list-users", dialect.MySQL, "SELECT * FROM `users`;"),
+		Entry("underscore suffix", "list-users", dialect.SQLite, "SELECT * FROM users;"),
+	)
+
+	It("returns the variant of its dialect", func() {
+		provider.SetDialect(dialect.Postgres)
+
+		query, err := provider.Query("find-user")
+		Expect(err).To(BeNil())
+		Expect(query).To(Equal("SELECT * FROM users WHERE id = $1::int;"))
+	})
+
+	Context("when the file suffix starts with a dialect name", func() {
+		It("returns the routine without a dialect", func() {
+			storage = fstest.MapFS{
+				"report_mysqlbackup.sql": &fstest.MapFile{
+					Data: []byte("-- name: backup-report\nSELECT * FROM backups;\n"),
+				},
+			}
+
+			provider = &sql.Provider{}
+			Expect(provider.ReadDir(storage)).To(Succeed())
+
+			for _, name := range []string{dialect.MySQL, dialect.Postgres} {
+				query, err := provider.QueryOf("backup-report", name)
+				Expect(err).To(BeNil())
+				Expect(query).To(Equal("SELECT * FROM backups;"))
+			}
+		})
+	})
+
+	Context("when the routine does not exist", func() {
+		It("returns an error", func() {
+			_, err := provider.QueryOf("delete-user", dialect.SQLite)
+			Expect(err).To(MatchError("query 'delete-user' not found"))
+		})
+	})
+
+	Context("when the routine has no variant for the dialect", func() {
+		It("returns an error", func() {
+			_, err := provider.QueryOf("list-users", dialect.Postgres)
+			Expect(err).To(MatchError("query 'list-users' not found for dialect postgres"))
+		})
+	})
+
+	Context("when the routine already exists", func() {
+		It("returns an error", func() {
+			_, err := provider.ReadFrom(strings.NewReader("-- name: find-user\nSELECT 1;\n"))
+			Expect(err).To(MatchError("query 'find-user' already exists"))
+
+			_, err = provider.ReadFrom(strings.NewReader("-- name: find-user\n-- dialect: pgx\nSELECT 1;\n"))
+			Expect(err).To(MatchError("query 'find-user' already exists for dialect postgres"))
+		})
+	})
+})
@@ -2,9 +2,12 @@ package sql
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"strings"
 
+	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql/scan"
 )
 
@@ -47,6 +50,10 @@ func (r *RoutineQuery) Dialect() string {
 // SetDialect sets the dialect
 func (r *RoutineQuery) SetDialect(dialect string) {
 	r.dialect = dialect
+
+	if r.stmt != nil {
+		r.stmt.SetDialect(dialect)
+	}
 }
 
 // SetQuery sets the query
@@ -74,6 +81,12 @@ func (r *RoutineQuery) Error() error {
 	return r.stmt.err
 }
 
+// Err returns the error of the arguments. It implements the same method as
+// the builders.
+func (r *RoutineQuery) Err() error {
+	return r.Error()
+}
+
 // A NamedArg is a named argument. NamedArg values may be used as
 // arguments to Query or Exec and bind to the corresponding named
 // parameter in the SQL statement.
@@ -82,25 +95,109 @@ func (r *RoutineQuery) Error() error {
 // the Named function.
 type NamedArg = sql.NamedArg
 
+// Named provides a more concise way to create NamedArg values.
+func Named(name string, value interface{}) NamedArg {
+	return sql.Named(name, value)
+}
+
+// Placeholder is an argument whose value is not known when the query is
+// built. It's bound to the named argument with the same name when the
+// prepared statement is executed.
+//
+//	query := Select().From(Table("users")).Where(EQ("id", Param("id")))
+type Placeholder string
+
+// Param creates a placeholder for the named argument with the given name.
+func Param(name string) Placeholder {
+	return Placeholder(name)
+}
+
+// Value implements the driver.Valuer interface. It always fails, because
+// the placeholders must be bound before the execution.
+func (p Placeholder) Value() (driver.Value, error) {
+	return nil, fmt.Errorf("dialect/sql: parameter %q is not bound", string(p))
+}
+
+// Bind returns a copy of the arguments where the placeholders are replaced
+// with the values of the named arguments. It returns an error if there is
+// no value for some of the placeholders.
+func Bind(args []interface{}, values ...NamedArg) ([]interface{}, error) {
+	lookup := func(name Placeholder) (interface{}, error) {
+		for _, value := range values {
+			if value.Name == string(name) {
+				return value.Value, nil
+			}
+		}
+
+		return nil, fmt.Errorf("dialect/sql: parameter %q is not bound", string(name))
+	}
+
+	argv := make([]interface{}, len(args))
+
+	for index, arg := range args {
+		switch param := arg.(type) {
+		case Placeholder:
+			value, err := lookup(param)
+			if err != nil {
+				return nil, err
+			}
+
+			argv[index] = value
+		case NamedArg:
+			if name, ok := param.Value.(Placeholder); ok {
+				value, err := lookup(name)
+				if err != nil {
+					return nil, err
+				}
+
+				param.Value = value
+			}
+
+			argv[index] = param
+		default:
+			argv[index] = arg
+		}
+	}
+
+	return argv, nil
+}
+
 var _ Querier = &NamedQuery{}
 
 // NamedQuery is a named query that uses named arguments
 type NamedQuery struct {
 	err     error
 	dialect string
+	source  string
+	params  []interface{}
 	query   string
 	args    []sql.NamedArg
 }
 
 // Query create a new named query
 func Query(query string, params ...interface{}) *NamedQuery {
-	query, columns := scan.NamedQuery(query)
-	// scane the arguments
-	args, err := scan.Args(params, columns...)
-	// create the query
 	querier := &NamedQuery{
-		err:   err,
-		query: query,
+		source: query,
+		params: params,
+	}
+
+	querier.parse()
+	return querier
+}
+
+// parse renames the parameters of the source query and binds them to the
+// arguments. The query is tokenized in the dialect of the query.
+func (r *NamedQuery) parse() {
+	query, columns, _ := scan.ParseNamedQuery(r.source, r.dialect)
+	// scane the arguments
+	args, err := scan.Args(r.params, columns...)
+
+	r.err = err
+	r.query = query
+	r.args = nil
+
+	if err != nil {
+		return
 	}
 
 	for index, name := range columns {
@@ -108,10 +205,13 @@ func Query(query string, params ...interface{}) *NamedQuery {
 			Name:  name,
 			Value: args[index],
 		}
-		querier.args = append(querier.args, param)
-	}
 
-	return querier
+		if value, ok := expandable(param.Value); ok && value.Len() == 0 {
+			r.err = fmt.Errorf("dialect/sql: parameter %q is an empty slice", name)
+		}
+
+		r.args = append(r.args, param)
+	}
 }
 
 // Dialect returns the dialect
@@ -119,9 +219,15 @@ func (r *NamedQuery) Dialect() string {
 	return r.dialect
 }
 
-// SetDialect sets the dialect
+// SetDialect sets the dialect. The query is parsed again, because the string
+// literals differ between the dialects (e.g. the backslash escapes of MySQL).
 func (r *NamedQuery) SetDialect(dialect string) {
+	if r.dialect == dialect {
+		return
+	}
+
 	r.dialect = dialect
+	r.parse()
 }
 
 // Total returns the total count of parameters
@@ -134,37 +240,120 @@ func (r *NamedQuery) Error() error {
 	return r.err
 }
 
+// Err returns the error of the arguments. It implements the same method as
+// the builders.
+func (r *NamedQuery) Err() error {
+	return r.err
+}
+
 // Query returns the routine
 // Query returns the query representation of the element
-// and its arguments (if any).
+// and its arguments (if any). The slices are expanded to a placeholder for
+// each element.
 func (r *NamedQuery) Query() (string, []interface{}) {
 	var (
-		query = r.query
-		args  = make([]interface{}, 0)
+		args         = make([]interface{}, 0)
+		index        = 0
+		placeholders = make(map[string]string)
+		style, named = r.placeholder()
 	)
 
-	for index, param := range r.args {
-		target := fmt.Sprintf(":%v", param.Name)
-
-		switch r.dialect {
-		case "postgres":
-			name := fmt.Sprintf("$%d", index+1)
-			query = strings.Replace(query, target, name, 1)
-			args = append(args, param.Value)
-		case "mysql", "sqlite":
-			name := "?"
-			query = strings.Replace(query, target, name, 1)
-			args = append(args, param.Value)
-		case "oci8", "ora", "goracle", "godror":
-			args = append(args, param)
-		case "sqlserver":
-			name := fmt.Sprintf("@%v", param.Name)
-			query = strings.Replace(query, target, name, 1)
-			args = append(args, param)
-		default:
-			args = append(args, param)
+	query := scan.Rewrite(r.query, r.dialect, func(name string) string {
+		target := fmt.Sprintf(":%v", name)
+		// the query is rewritten by the same lexer as in the constructor
+		if index >= len(r.args) {
+			return target
 		}
-	}
+
+		param := r.args[index]
+		index++
+
+		// the repeated parameters reuse the same placeholders
+		if placeholder, ok := placeholders[param.Name]; ok {
+			return placeholder
+		}
+
+		var (
+			items  = expand(param)
+			values = make([]string, len(items))
+		)
+
+		for i, item := range items {
+			switch {
+			case !named:
+				args = append(args, item)
+				values[i] = fmt.Sprintf(":%v", item.Name)
+			case style == dialect.PlaceholderAt:
+				args = append(args, item)
+				values[i] = fmt.Sprintf("@%v", item.Name)
+			default:
+				args = append(args, item.Value)
+				values[i] = style.Format(len(args))
+			}
+		}
+
+		placeholder := strings.Join(values, ", ")
+
+		// the question marks cannot be referenced more than once
+		if named && style != dialect.PlaceholderQuestion {
+			placeholders[param.Name] = placeholder
+		}
+
+		return placeholder
+	})
 
 	return query, args
 }
+
+// placeholder returns the placeholder style of the dialect. It reports false
+// if the dialect is not registered. In that case, the parameters are passed
+// as named arguments (e.g. :name).
+func (r *NamedQuery) placeholder() (dialect.Placeholder, bool) {
+	if d, ok := dialect.Lookup(r.dialect); ok {
+		return d.Placeholder(), true
+	}
+
+	return dialect.PlaceholderQuestion, false
+}
+
+// expand returns an argument for each element of the slice. The elements are
+// named <name>_0, <name>_1 and so on. The dots of the nested paths are
+// replaced with underscores, because the drivers do not allow them in the
+// names.
+func expand(param NamedArg) []NamedArg {
+	name := strings.ReplaceAll(param.Name, ".", "_")
+
+	value, ok := expandable(param.Value)
+	if !ok {
+		return []NamedArg{Named(name, param.Value)}
+	}
+
+	items := make([]NamedArg, value.Len())
+
+	for i := range items {
+		items[i] = Named(fmt.Sprintf("%s_%d", name, i), value.Index(i).Interface())
+	}
+
+	return items
+}
+
+// expandable returns the slice or the array that should be expanded. The
+// byte slices and the driver.Valuer implementations are kept as they are.
+func expandable(value interface{}) (reflect.Value, bool) {
+	if _, ok := value.(driver.Valuer); ok {
+		return reflect.Value{}, false
+	}
+
+	param := reflect.Indirect(reflect.ValueOf(value))
+
+	switch param.Kind() {
+	case reflect.Slice, reflect.Array:
+		if param.Type().Elem().Kind() == reflect.Uint8 {
+			return reflect.Value{}, false
+		}
+
+		return param, true
+	default:
+		return reflect.Value{}, false
+	}
+}
@@ -25,6 +25,154 @@ var _ = Describe("RoutineQuerier", func() {
 })
 
 var _ = Describe("NamedQuerier", func() {
+	Context("when the query has backslashes in the strings", func() {
+		It("tokenizes them in the dialect", func() {
+			routine := sql.Query(`SELECT * FROM files WHERE path = 'C:\' AND id = :id`, sql.Named("id", 1))
+			routine.SetDialect("postgres")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal(`SELECT * FROM files WHERE path = 'C:\' AND id = $1`))
+			Expect(params).To(Equal([]interface{}{1}))
+		})
+
+		It("allows the backslash escapes for MySQL", func() {
+			routine := sql.Query(`SELECT * FROM files WHERE name = 'it\'s :name' AND id = :id`, sql.Named("id", 1))
+			routine.SetDialect("mysql")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal(`SELECT * FROM files WHERE name = 'it\'s :name' AND id = ?`))
+			Expect(params).To(Equal([]interface{}{1}))
+		})
+	})
+
+	Context("when the argument is a slice", func() {
+		It("expands it for PostgreSQL", func() {
+			routine := sql.Query("SELECT * FROM users WHERE id IN (:ids) AND parent_id IN (:ids) AND name = ?", sql.Named("ids", []int{1, 2}), "root")
+			routine.SetDialect("postgres")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id IN ($1, $2) AND parent_id IN ($1, $2) AND name = $3"))
+			Expect(params).To(Equal([]interface{}{1, 2, "root"}))
+		})
+
+		It("expands it for MySQL", func() {
+			routine := sql.Query("SELECT * FROM users WHERE id IN (?)", []string{"a", "b"})
+			routine.SetDialect("mysql")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id IN (?, ?)"))
+			Expect(params).To(Equal([]interface{}{"a", "b"}))
+		})
+
+		It("expands it to named arguments for SQL Server", func() {
+			routine := sql.Query("SELECT * FROM users WHERE id IN (:ids)", map[string]interface{}{"ids": []int{1, 2}})
+			routine.SetDialect("sqlserver")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id IN (@ids_0, @ids_1)"))
+			Expect(params).To(Equal([]interface{}{sql.Named("ids_0", 1), sql.Named("ids_1", 2)}))
+		})
+
+		It("does not expand the byte slices", func() {
+			routine := sql.Query("SELECT * FROM users WHERE avatar = ?", []byte("data"))
+			routine.SetDialect("mysql")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE avatar = ?"))
+			Expect(params).To(Equal([]interface{}{[]byte("data")}))
+		})
+
+		Context("when the slice is empty", func() {
+			It("returns an error", func() {
+				routine := sql.Query("SELECT * FROM users WHERE id IN (:ids)", sql.Named("ids", []int{}))
+				Expect(routine.Err()).To(MatchError(`dialect/sql: parameter "ids" is an empty slice`))
+			})
+		})
+	})
+
+	Context("when the parameter is a nested path", func() {
+		type Group struct {
+			ID   int    `db:"id"`
+			Name string `db:"name"`
+		}
+
+		type Member struct {
+			Name  string            `db:"name"`
+			Group *Group            `db:"group"`
+			Meta  map[string]string `db:"meta"`
+		}
+
+		It("resolves the path", func() {
+			member := &Member{
+				Name:  "root",
+				Group: &Group{ID: 7, Name: "admins"},
+				Meta:  map[string]string{"role": "owner"},
+			}
+
+			routine := sql.Query("SELECT * FROM users WHERE group_id = :user.group.id AND name = :user.name AND role = :user.meta.role", sql.Named("user", member))
+			routine.SetDialect("sqlserver")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE group_id = @user_group_id AND name = @user_name AND role = @user_meta_role"))
+			Expect(params).To(Equal([]interface{}{
+				sql.Named("user_group_id", 7),
+				sql.Named("user_name", "root"),
+				sql.Named("user_meta_role", "owner"),
+			}))
+		})
+
+		It("resolves the path behind a nil pointer to nil", func() {
+			routine := sql.Query("SELECT * FROM users WHERE group_id = :group.id", &Member{Name: "root"})
+			routine.SetDialect("mysql")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE group_id = ?"))
+			Expect(params).To(Equal([]interface{}{nil}))
+		})
+	})
+
+	Context("when the names are prefixes of each other", func() {
+		It("replaces the right occurrence", func() {
+			routine := sql.Query("SELECT * FROM users WHERE id2 = :id2 AND id = :id AND parent_id = :id", sql.Named("id", 1), sql.Named("id2", 2))
+			routine.SetDialect("postgres")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id2 = $1 AND id = $2 AND parent_id = $2"))
+			Expect(params).To(Equal([]interface{}{2, 1}))
+		})
+	})
+
+	Context("when the query has a cast", func() {
+		It("keeps the cast", func() {
+			routine := sql.Query("SELECT * FROM users WHERE created_at::date = ? AND name = ':name'", "2026-01-01")
+			routine.SetDialect("postgres")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE created_at::date = $1 AND name = ':name'"))
+			Expect(params).To(Equal([]interface{}{"2026-01-01"}))
+		})
+	})
+
+	Context("when the parameter is repeated", func() {
+		It("passes it once for SQL Server", func() {
+			routine := sql.Query("SELECT * FROM users WHERE id = :id OR parent_id = :id", sql.Named("id", 1))
+			routine.SetDialect("sqlserver")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id = @id OR parent_id = @id"))
+			Expect(params).To(Equal([]interface{}{sql.Named("id", 1)}))
+		})
+
+		It("passes it for each occurrence for MySQL", func() {
+			routine := sql.Query("SELECT * FROM users WHERE id = :id OR parent_id = :id", sql.Named("id", 1))
+			routine.SetDialect("mysql")
+
+			query, params := routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id = ? OR parent_id = ?"))
+			Expect(params).To(Equal([]interface{}{1, 1}))
+		})
+	})
+
 	Context("when the provided argument is single", func() {
 		It("creates new command successfully", func() {
 			routine := sql.Query("SELECT * FROM users WHERE id = ?", 5432)
@@ -121,6 +269,11 @@ var _ = Describe("NamedQuerier", func() {
 			Expect(params).To(ContainElement(99))
 			Expect(params).To(ContainElement("nuts"))
 
+			routine.SetDialect("sqlite3")
+			query, params = routine.Query()
+			Expect(query).To(Equal("SELECT * FROM users WHERE id = ? AND category_id > ? AND category_name = ?"))
+			Expect(params).To(Equal([]interface{}{1234, 99, "nuts"}))
+
 			routine.SetDialect("sqlite")
 			query, params = routine.Query()
 			Expect(query).To(Equal("SELECT * FROM users WHERE id = ? AND category_id > ? AND category_name = ?"))
@@ -138,3 +291,36 @@ var _ = Describe("NamedQuerier", func() {
 		})
 	})
 })
+
+var _ = Describe("Bind", func() {
+	It("binds the placeholders", func() {
+		query, args := sql.Select().
+			From(sql.Table("users")).
+			Where(sql.And(sql.EQ("id", sql.Param("id")), sql.GT("age", 18))).
+			Query()
+
+		Expect(query).To(Equal("SELECT * FROM `users` WHERE `id` = ? AND `age` > ?"))
+
+		params, err := sql.Bind(args, sql.Named("id", 5))
+		Expect(err).To(Succeed())
+		Expect(params).To(Equal([]interface{}{5, 18}))
+		// the original arguments are not modified
+		Expect(args[0]).To(Equal(sql.Param("id")))
+	})
+
+	It("binds the placeholders of the named arguments", func() {
+		_, args := sql.Query("SELECT * FROM users WHERE id = :id", sql.Param("id")).Query()
+
+		params, err := sql.Bind(args, sql.Named("id", 5))
+		Expect(err).To(Succeed())
+		Expect(params).To(Equal([]interface{}{sql.Named("id", 5)}))
+	})
+
+	Context("when the argument is missing", func() {
+		It("returns an error", func() {
+			params, err := sql.Bind([]interface{}{sql.Param("id")}, sql.Named("name", "root"))
+			Expect(err).To(MatchError(`dialect/sql: parameter "id" is not bound`))
+			Expect(params).To(BeNil())
+		})
+	})
+})
@@ -34,6 +34,8 @@ func (r *Allocator) Allocate() []interface{} {
 // Set sets the values
 func (r *Allocator) Set(value, next reflect.Value, columns []string) {
 	switch {
+	case value.Kind() == reflect.Ptr && value.IsNil():
+		value.Set(next)
 	case value.Kind() == reflect.Ptr:
 		r.Set(value.Elem(), next.Elem(), columns)
 	case value.Kind() == reflect.Struct:
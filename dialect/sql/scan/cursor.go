@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Cursor scans the rows of a Scanner one at a time
+type Cursor struct {
+	scanner   Scanner
+	columns   []string
+	target    reflect.Type
+	allocator *Allocator
+}
+
+// NewCursor creates a new cursor for the given scanner
+func NewCursor(scanner Scanner) (*Cursor, error) {
+	columns, err := scanner.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("sql/scan: failed getting column names: %v", err)
+	}
+
+	cursor := &Cursor{
+		scanner: scanner,
+		columns: columns,
+	}
+
+	return cursor, nil
+}
+
+// Next prepares the next row for scanning. It returns false when there are
+// no more rows.
+func (c *Cursor) Next() bool {
+	return c.scanner.Next()
+}
+
+// Scan scans the current row into the given value
+func (c *Cursor) Scan(src interface{}) error {
+	value, err := valueOf(src)
+	if err != nil {
+		return err
+	}
+
+	// the allocator is reused as long as the target type does not change
+	if c.allocator == nil || c.target != value.Type() {
+		allocator, err := NewAllocator(value.Type(), c.columns)
+		if err != nil {
+			return err
+		}
+
+		if expected, actual := len(c.columns), len(allocator.types); expected > actual {
+			return fmt.Errorf("sql/scan: columns do not match (%d > %d)", expected, actual)
+		}
+
+		c.target = value.Type()
+		c.allocator = allocator
+	}
+
+	values := c.allocator.Allocate()
+	if err := c.scanner.Scan(values...); err != nil {
+		return fmt.Errorf("sql/scan: failed scanning rows: %v", err)
+	}
+
+	next := c.allocator.Create(values)
+	c.allocator.Set(value, next, c.columns)
+
+	return nil
+}
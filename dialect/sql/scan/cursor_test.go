@@ -0,0 +1,92 @@
+package scan_test
+
+import (
+	"database/sql"
+
+	"github.com/phogolabs/orm/dialect/sql/scan"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cursor", func() {
+	var db *sql.DB
+
+	type User struct {
+		ID   int     `db:"id"`
+		Name *string `db:"name"`
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = sql.Open("sqlite3", "file:test.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("CREATE TABLE IF NOT EXISTS users (id int, name varchar(255), password varchar(10))")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("INSERT INTO users VALUES(1, 'root', 'swordfish'), (2, 'admin', 'secret')")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		_, err := db.Exec("DELETE FROM users")
+		Expect(err).To(BeNil())
+
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("scans the rows one by one", func() {
+		rows, err := db.Query("SELECT id, name FROM users ORDER BY id")
+		Expect(err).To(BeNil())
+		defer rows.Close()
+
+		cursor, err := scan.NewCursor(rows)
+		Expect(err).To(BeNil())
+
+		users := []*User{}
+
+		for cursor.Next() {
+			user := &User{}
+			Expect(cursor.Scan(user)).To(Succeed())
+			users = append(users, user)
+		}
+
+		Expect(users).To(HaveLen(2))
+		Expect(users[0].ID).To(Equal(1))
+		Expect(*users[0].Name).To(Equal("root"))
+		Expect(users[1].ID).To(Equal(2))
+		Expect(*users[1].Name).To(Equal("admin"))
+	})
+
+	Context("when the columns do not match", func() {
+		It("returns an error", func() {
+			rows, err := db.Query("SELECT id, name FROM users ORDER BY id")
+			Expect(err).To(BeNil())
+			defer rows.Close()
+
+			cursor, err := scan.NewCursor(rows)
+			Expect(err).To(BeNil())
+			Expect(cursor.Next()).To(BeTrue())
+
+			var id int
+			Expect(cursor.Scan(&id)).To(MatchError("sql/scan: columns do not match (2 > 1)"))
+		})
+	})
+
+	Context("when the value is not a pointer", func() {
+		It("returns an error", func() {
+			rows, err := db.Query("SELECT id, name FROM users ORDER BY id")
+			Expect(err).To(BeNil())
+			defer rows.Close()
+
+			cursor, err := scan.NewCursor(rows)
+			Expect(err).To(BeNil())
+			Expect(cursor.Next()).To(BeTrue())
+
+			Expect(cursor.Scan(User{})).To(MatchError("sql/scan: invalid type struct. expected pointer as an argument"))
+		})
+	})
+})
@@ -1,82 +1,79 @@
 package scan
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"strings"
-	"unicode"
+
+	"github.com/phogolabs/orm/dialect"
 )
 
-// NamedQuery returns the query renamed
+// NamedQuery returns the query renamed. The positional parameters (?) are
+// renamed to :arg0, :arg1 and so on. It returns the names of the parameters
+// in the order of their occurrence. A name is returned for each occurrence of
+// a repeated parameter.
+//
+// The parameters inside string literals, quoted identifiers, comments and
+// dollar-quoted strings are ignored as well as the PostgreSQL casts (::) and
+// the JSON operators (?| and ?&). The question mark can be escaped as ??. The
+// query is tokenized as standard SQL.
 func NamedQuery(query string) (string, []string) {
+	query, params, _ := ParseNamedQuery(query, "")
+	return query, params
+}
+
+// ParseNamedQuery is like NamedQuery, but it tokenizes the query in the given
+// dialect (e.g. the backslash escapes of MySQL). It returns an error if the
+// query has a string literal, a quoted identifier, a block comment or a
+// dollar-quoted string that is not terminated. The parameters that follow it
+// are lost.
+func ParseNamedQuery(query, name string) (string, []string, error) {
 	var (
-		buffer = &bytes.Buffer{}
-		next   int
+		buffer = &strings.Builder{}
+		params = []string{}
+		next   = 0
 	)
 
-	for index := strings.Index(query, "?"); index != -1; index = strings.Index(query, "?") {
-		fmt.Fprint(buffer, query[:index])
-		fmt.Fprint(buffer, ":")
-		fmt.Fprintf(buffer, "arg%d", next)
-
-		query = query[index+1:]
-		next++
+	tokens, err := dialect.Tokenize(query, name)
+
+	for _, token := range tokens {
+		switch token.Kind {
+		case dialect.TokenPositional:
+			param := fmt.Sprintf("arg%d", next)
+			params = append(params, param)
+			next++
+
+			buffer.WriteString(":" + param)
+		case dialect.TokenNamed:
+			params = append(params, token.Name)
+			buffer.WriteString(token.Raw)
+		default:
+			buffer.WriteString(token.Raw)
+		}
 	}
 
-	fmt.Fprint(buffer, query)
-
-	query = buffer.String()
+	return buffer.String(), params, err
+}
 
+// Rewrite replaces each occurrence of the named parameters in the query with
+// the result of the given function. The escaped question marks (??) are
+// unescaped. The query is tokenized in the given dialect the same way as in
+// ParseNamedQuery.
+func Rewrite(query, name string, fn func(name string) string) string {
 	var (
-		params     = []string{}
-		underscore = '_'
-		runes      = []*unicode.RangeTable{
-			unicode.Letter,
-			unicode.Digit,
-		}
+		buffer    = &strings.Builder{}
+		tokens, _ = dialect.Tokenize(query, name)
 	)
 
-	scanner := bufio.NewScanner(buffer)
-	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
-		for index := 0; index < len(data); index++ {
-			if data[index] == ':' {
-				return index + 1, data[:index+1], nil
-			}
-		}
-
-		if !atEOF {
-			return 0, nil, nil
-		}
-
-		return 0, data, bufio.ErrFinalToken
-	})
-
-	for scanner.Scan() {
-		part := scanner.Text()
-		tail := part[len(part)-1]
-
-		for tail == ':' {
-			if !scanner.Scan() {
-				break
-			}
-
-			part = scanner.Text()
-			tail = part[len(part)-1]
-
-			param := &bytes.Buffer{}
-
-			for _, ch := range part {
-				if !unicode.IsOneOf(runes, ch) && ch != underscore {
-					break
-				}
-
-				fmt.Fprint(param, string(ch))
-			}
-
-			params = append(params, param.String())
+	for _, token := range tokens {
+		switch token.Kind {
+		case dialect.TokenNamed:
+			buffer.WriteString(fn(token.Name))
+		case dialect.TokenEscape:
+			buffer.WriteString("?")
+		default:
+			buffer.WriteString(token.Raw)
 		}
 	}
 
-	return query, params
+	return buffer.String()
 }
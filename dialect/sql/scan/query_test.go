@@ -1,6 +1,7 @@
 package scan_test
 
 import (
+	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql/scan"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -68,4 +69,117 @@ var _ = Describe("NamedQuery", func() {
 			Expect(params[2]).To(Equal("arg1"))
 		})
 	})
+
+	Context("when the parameters are nested paths", func() {
+		It("returns the paths", func() {
+			query, params := scan.NamedQuery("SELECT * FROM t WHERE id = :user.group.id AND name = :user.name. AND x = :a.1")
+			Expect(query).To(Equal("SELECT * FROM t WHERE id = :user.group.id AND name = :user.name. AND x = :a.1"))
+			Expect(params).To(Equal([]string{"user.group.id", "user.name", "a"}))
+		})
+	})
+
+	Context("when the query has string literals", func() {
+		It("does not rename the parameters inside them", func() {
+			query, params := scan.NamedQuery(`SELECT 'a ? :b', "c?:d", ` + "`e?`" + `, 'it''s ?' FROM t WHERE id = ?`)
+			Expect(query).To(Equal(`SELECT 'a ? :b', "c?:d", ` + "`e?`" + `, 'it''s ?' FROM t WHERE id = :arg0`))
+			Expect(params).To(Equal([]string{"arg0"}))
+		})
+	})
+
+	Context("when the query has comments", func() {
+		It("does not rename the parameters inside them", func() {
+			query, params := scan.NamedQuery("SELECT * -- is it :name?\nFROM t /* :id ? */ WHERE id = :id")
+			Expect(query).To(Equal("SELECT * -- is it :name?\nFROM t /* :id ? */ WHERE id = :id"))
+			Expect(params).To(Equal([]string{"id"}))
+		})
+	})
+
+	Context("when the query has dollar-quoted strings", func() {
+		It("does not rename the parameters inside them", func() {
+			query, params := scan.NamedQuery("SELECT $$ :a ? $$, $body$ :b $$ ? $body$, price$ FROM t WHERE id = $1 AND name = :name")
+			Expect(query).To(Equal("SELECT $$ :a ? $$, $body$ :b $$ ? $body$, price$ FROM t WHERE id = $1 AND name = :name"))
+			Expect(params).To(Equal([]string{"name"}))
+		})
+	})
+
+	Context("when the query has PostgreSQL casts and operators", func() {
+		It("does not rename them", func() {
+			query, params := scan.NamedQuery("SELECT created_at::date FROM t WHERE tags ?| :tags AND tags ?& ? AND data ?? 'key' AND id = :id::int")
+			Expect(query).To(Equal("SELECT created_at::date FROM t WHERE tags ?| :tags AND tags ?& :arg0 AND data ?? 'key' AND id = :id::int"))
+			Expect(params).To(Equal([]string{"tags", "arg0", "id"}))
+		})
+	})
+})
+
+var _ = Describe("ParseNamedQuery", func() {
+	It("returns the query and the parameters", func() {
+		query, params, err := scan.ParseNamedQuery("SELECT * FROM t WHERE id = ? AND name = :name", "")
+		Expect(err).To(BeNil())
+		Expect(query).To(Equal("SELECT * FROM t WHERE id = :arg0 AND name = :name"))
+		Expect(params).To(Equal([]string{"arg0", "name"}))
+	})
+
+	Context("when the dialect is mysql", func() {
+		It("allows the backslash escapes in the strings", func() {
+			query, params, err := scan.ParseNamedQuery(`SELECT 'it\'s :x', "a \" :y" FROM t WHERE id = :id`, dialect.MySQL)
+			Expect(err).To(BeNil())
+			Expect(query).To(Equal(`SELECT 'it\'s :x', "a \" :y" FROM t WHERE id = :id`))
+			Expect(params).To(Equal([]string{"id"}))
+		})
+
+		It("does not nest the block comments", func() {
+			query, params, err := scan.ParseNamedQuery("/* /* */ SELECT * FROM t WHERE id = ?", dialect.MySQL)
+			Expect(err).To(BeNil())
+			Expect(query).To(Equal("/* /* */ SELECT * FROM t WHERE id = :arg0"))
+			Expect(params).To(Equal([]string{"arg0"}))
+		})
+	})
+
+	Context("when the dialect is postgres", func() {
+		It("does not allow the backslash escapes in the standard strings", func() {
+			query, params, err := scan.ParseNamedQuery(`SELECT * FROM t WHERE path = 'C:\' AND id = :id`, dialect.Postgres)
+			Expect(err).To(BeNil())
+			Expect(query).To(Equal(`SELECT * FROM t WHERE path = 'C:\' AND id = :id`))
+			Expect(params).To(Equal([]string{"id"}))
+		})
+
+		It("allows the backslash escapes in the escape strings", func() {
+			query, params, err := scan.ParseNamedQuery(`SELECT * FROM t WHERE name = E'it\'s :x' AND id = :id`, dialect.Postgres)
+			Expect(err).To(BeNil())
+			Expect(query).To(Equal(`SELECT * FROM t WHERE name = E'it\'s :x' AND id = :id`))
+			Expect(params).To(Equal([]string{"id"}))
+		})
+
+		It("nests the block comments", func() {
+			query, params, err := scan.ParseNamedQuery("SELECT * FROM t /* :id ? /* nested ? */ :id */ WHERE id = :id", dialect.Postgres)
+			Expect(err).To(BeNil())
+			Expect(query).To(Equal("SELECT * FROM t /* :id ? /* nested ? */ :id */ WHERE id = :id"))
+			Expect(params).To(Equal([]string{"id"}))
+		})
+	})
+
+	DescribeTable("returns an error when the query is not terminated",
+		func(query, message string) {
+			_, _, err := scan.ParseNamedQuery(query, "")
+			Expect(err).To(MatchError(message))
+		},
+		Entry("quoted string", "SELECT * FROM t WHERE name = ':name", "dialect: unterminated quoted string at position 29"),
+		Entry("quoted identifier", "SELECT `name FROM t", "dialect: unterminated quoted identifier at position 7"),
+		Entry("block comment", "SELECT * FROM t /* :id", "dialect: unterminated block comment at position 16"),
+		Entry("dollar-quoted string", "SELECT $body$ :id FROM t", "dialect: unterminated dollar-quoted string at position 7"),
+	)
+})
+
+var _ = Describe("Rewrite", func() {
+	It("replaces each occurrence of the parameters", func() {
+		names := []string{}
+
+		query := scan.Rewrite("SELECT ':id' FROM t WHERE id = :id OR id2 = :id2 OR parent = :id AND data ?? 'key'", "", func(name string) string {
+			names = append(names, name)
+			return "$" + name
+		})
+
+		Expect(query).To(Equal("SELECT ':id' FROM t WHERE id = $id OR id2 = $id2 OR parent = $id AND data ? 'key'"))
+		Expect(names).To(Equal([]string{"id", "id2", "id"}))
+	})
 })
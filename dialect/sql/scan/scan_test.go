@@ -81,6 +81,22 @@ var _ = Describe("Scan", func() {
 				Expect(group.User.ID).To(Equal(7))
 			})
 		})
+
+		Context("when the target is a nil pointer", func() {
+			It("scans the row successfully", func() {
+				_, err := db.Exec("INSERT INTO users VALUES(1, 'root', 'swordfish')")
+				Expect(err).To(BeNil())
+
+				var user *User
+
+				rows, err := db.Query("SELECT * FROM users")
+				Expect(err).To(BeNil())
+
+				Expect(scan.Row(rows, &user)).To(Succeed())
+				Expect(user).NotTo(BeNil())
+				Expect(user.ID).To(Equal(1))
+			})
+		})
 	})
 
 	Describe("Rows", func() {
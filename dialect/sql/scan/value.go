@@ -2,15 +2,20 @@ package scan
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/phogolabs/orm/dialect"
 )
 
 var (
 	mapper       = reflectx.NewMapper("db")
 	namedArgType = reflect.TypeOf(sql.NamedArg{})
+	timeType     = reflect.TypeOf(time.Time{})
 )
 
 // IsNil returns true if the value is nil
@@ -66,32 +71,169 @@ func IsEmpty(src interface{}) bool {
 }
 
 // Args returns the arguments
+//
+// If the columns are provided, it returns a value for each column in their
+// order. The value is looked up by name in the named arguments, structs and
+// maps. The dotted names like user.group.id are paths into the nested
+// structs and maps. Otherwise, the next positional argument of a simple type
+// (or a slice) is used.
 func Args(src []interface{}, columns ...string) ([]interface{}, error) {
 	if len(columns) == 0 {
 		return src, nil
 	}
 
-	args := make([]interface{}, 0)
+	var (
+		args       = make([]interface{}, 0, len(columns))
+		positional = []interface{}{}
+		sources    = []reflect.Value{}
+	)
 
 	for _, arg := range src {
-		param := reflect.ValueOf(arg)
-		param = reflect.Indirect(param)
+		param := reflect.Indirect(reflect.ValueOf(arg))
 
-		switch k := param.Type().Kind(); {
+		_, valuer := arg.(driver.Valuer)
+
+		switch k := param.Kind(); {
+		case k == reflect.Invalid || valuer || param.Type() == timeType:
+			positional = append(positional, arg)
 		case k == reflect.String || k >= reflect.Bool && k <= reflect.Float64:
-			args = append(args, arg)
+			positional = append(positional, arg)
+		case k == reflect.Slice || k == reflect.Array:
+			// the slices are expanded by the query
+			positional = append(positional, arg)
 		default:
-			values, err := valuesOf(param, columns)
-			if err != nil {
-				return nil, err
-			}
-			args = append(args, values...)
+			sources = append(sources, param)
+		}
+	}
+
+	for _, column := range columns {
+		value, ok, err := lookup(sources, column)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case ok:
+			args = append(args, value)
+		case len(positional) > 0:
+			args = append(args, positional[0])
+			positional = positional[1:]
+		default:
+			return nil, fmt.Errorf("sql/scan: missing value for parameter %q", column)
 		}
 	}
 
 	return args, nil
 }
 
+// lookup returns the value of the column from the first source that has it.
+func lookup(sources []reflect.Value, column string) (interface{}, bool, error) {
+	for _, source := range sources {
+		value, ok, err := valueByPath(source, column)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if ok {
+			return value, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// valueByPath returns the value at the given dotted path of a named argument,
+// struct or map. The nested pointers are not allocated.
+func valueByPath(source reflect.Value, path string) (interface{}, bool, error) {
+	for source.Kind() == reflect.Ptr || source.Kind() == reflect.Interface {
+		if source.IsNil() {
+			return nil, false, nil
+		}
+
+		source = source.Elem()
+	}
+
+	switch source.Kind() {
+	case reflect.Struct:
+		switch source.Type() {
+		case namedArgType:
+			arg := source.Interface().(sql.NamedArg)
+
+			switch {
+			case path == arg.Name:
+				return arg.Value, true, nil
+			case strings.HasPrefix(path, arg.Name+"."):
+				return valueByPath(reflect.ValueOf(arg.Value), strings.TrimPrefix(path, arg.Name+"."))
+			default:
+				return nil, false, nil
+			}
+		case timeType:
+			return nil, false, nil
+		}
+
+		// the mapper resolves the paths of the nested structs
+		if field := fieldByName(source.Type(), path); field != nil {
+			value, ok := fieldByIndex(source, field.Index)
+			if !ok {
+				return nil, true, nil
+			}
+
+			// mask the value in the logs
+			if _, ok := field.Options["sensitive"]; ok {
+				return dialect.Sensitive(value.Interface()), true, nil
+			}
+
+			return value.Interface(), true, nil
+		}
+	case reflect.Map:
+		if keyKind := source.Type().Key().Kind(); keyKind != reflect.String {
+			return nil, false, fmt.Errorf("sql/scan: invalid type %s. expected string as an key", keyKind)
+		}
+
+		key := reflect.ValueOf(path).Convert(source.Type().Key())
+
+		if value := source.MapIndex(key); value.IsValid() {
+			return value.Interface(), true, nil
+		}
+	default:
+		return nil, false, fmt.Errorf("sql/scan: invalid type %s. expected struct or map as an argument", source.Kind())
+	}
+
+	// the path continues in a nested map or in a struct behind an interface
+	for index := strings.LastIndex(path, "."); index > 0; index = strings.LastIndex(path[:index], ".") {
+		value, ok, err := valueByPath(source, path[:index])
+		if err != nil || !ok {
+			continue
+		}
+
+		if value == nil {
+			return nil, true, nil
+		}
+
+		return valueByPath(reflect.ValueOf(value), path[index+1:])
+	}
+
+	return nil, false, nil
+}
+
+// fieldByIndex returns the nested field of the struct. It returns false if
+// the field is behind a nil pointer.
+func fieldByIndex(target reflect.Value, vector []int) (reflect.Value, bool) {
+	for depth, index := range vector {
+		if depth > 0 && target.Kind() == reflect.Ptr {
+			if target.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			target = target.Elem()
+		}
+
+		target = target.Field(index)
+	}
+
+	return target, true
+}
+
 // Values scans a struct and returns the values associated with the columns
 // provided. Only simple value types are supported (i.e. Bool, Ints, Uints,
 // Floats, Interface, String, NamedArg)
@@ -153,6 +295,10 @@ func valuesOfStruct(target reflect.Value, columns []string) ([]interface{}, erro
 		if field := fieldByName(target.Type(), name); field != nil {
 			// find the value
 			value := valueByIndex(target, field.Index).Interface()
+			// mask the value in the logs
+			if _, ok := field.Options["sensitive"]; ok {
+				value = dialect.Sensitive(value)
+			}
 			// append it
 			values = append(values, value)
 		}
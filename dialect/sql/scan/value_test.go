@@ -197,4 +197,32 @@ var _ = Describe("Args", func() {
 			Expect(values[2]).To(Equal("swordfish"))
 		})
 	})
+
+	Context("when the columns are repeated", func() {
+		It("returns a value for each column in their order", func() {
+			now := time.Now()
+
+			args := []interface{}{
+				sql.NamedArg{Name: "name", Value: "root"},
+				now,
+				sql.NamedArg{Name: "id", Value: 1},
+			}
+
+			values, err := scan.Args(args, "id", "name", "arg0", "id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal([]interface{}{1, "root", now, 1}))
+		})
+	})
+
+	Context("when a value is missing", func() {
+		It("returns an error", func() {
+			args := []interface{}{
+				sql.NamedArg{Name: "id", Value: 1},
+			}
+
+			values, err := scan.Args(args, "id", "name")
+			Expect(err).To(MatchError(`sql/scan: missing value for parameter "name"`))
+			Expect(values).To(BeNil())
+		})
+	})
 })
@@ -0,0 +1,557 @@
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/inflect"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/phogolabs/orm/dialect"
+)
+
+var (
+	schemaMapper = reflectx.NewMapper("db")
+	timeType     = reflect.TypeOf(time.Time{})
+	jsonType     = reflect.TypeOf(json.RawMessage{})
+)
+
+// TableNamer is implemented by the entities that define the name of their
+// table. Otherwise, the table name is the pluralized snake case name of the
+// entity type.
+type TableNamer interface {
+	// TableName returns the table name
+	TableName() string
+}
+
+// CreateTableFrom returns a query builder for the `CREATE TABLE` statement of
+// the given entity. See DialectBuilder.DescribeEntity for the supported tag
+// options.
+//
+//	CreateTableFrom(&User{}).IfNotExists()
+func CreateTableFrom(entity interface{}) *TableBuilder {
+	return Dialect("").CreateTableFrom(entity)
+}
+
+// CreateIndexesFrom returns the query builders for the `CREATE INDEX`
+// statements of the given entity. See DialectBuilder.DescribeEntity for the
+// supported tag options.
+func CreateIndexesFrom(entity interface{}) []*IndexBuilder {
+	return Dialect("").CreateIndexesFrom(entity)
+}
+
+// EntityTable describes the table of an entity. It's created from the `db`
+// tags of the entity by DialectBuilder.DescribeEntity.
+type EntityTable struct {
+	// Name is the name of the table.
+	Name string
+	// Columns are the columns of the table.
+	Columns []*EntityColumn
+	// PrimaryKey are the columns of the primary key.
+	PrimaryKey []string
+	// Indexes are the indexes of the table.
+	Indexes []*EntityIndex
+	// ForeignKeys are the foreign keys of the table.
+	ForeignKeys []*EntityForeignKey
+}
+
+// EntityColumn describes a column of an entity table.
+type EntityColumn struct {
+	// Name is the name of the column.
+	Name string
+	// Type is the column type for the dialect.
+	Type string
+	// Nullable reports whether the column accepts NULL values.
+	Nullable bool
+	// Unique reports whether the column has a unique constraint.
+	Unique bool
+	// AutoIncrement reports whether the column is auto incremented.
+	AutoIncrement bool
+	// Default is the default value expression (if any).
+	Default string
+}
+
+// EntityIndex describes an index of an entity table.
+type EntityIndex struct {
+	// Name is the name of the index.
+	Name string
+	// Unique reports whether the index is unique.
+	Unique bool
+	// Columns are the indexed columns.
+	Columns []string
+}
+
+// EntityForeignKey describes a foreign key of an entity table.
+type EntityForeignKey struct {
+	// Columns are the columns of the foreign key.
+	Columns []string
+	// RefTable is the referenced table.
+	RefTable string
+	// RefColumns are the referenced columns.
+	RefColumns []string
+	// OnDelete is the ON DELETE action (if any).
+	OnDelete string
+}
+
+// DescribeEntity describes the table of the given entity for the configured
+// dialect. The column types are mapped from the Go types. The pointers and
+// sql.Null types are nullable columns. The tag supports the following
+// options:
+//
+//	primary_key                  - the column is part of the primary key
+//	not_null                     - the column is not nullable
+//	unique                       - the column has a unique constraint
+//	auto                         - the column is auto incremented or defaults to the current time
+//	type=<type>                  - the column type
+//	size=<size>                  - the size of the string column
+//	index[=<name>]               - the column is part of the index
+//	unique_index[=<name>]        - the column is part of the unique index
+//	foreign_key=<column>         - the column references the table of the struct field
+//	reference_key=<column>       - the referenced column of the foreign key
+//	on_delete=<action>           - the ON DELETE action of the foreign key (e.g. cascade or set_null)
+//
+// The columns with the same index name are part of a composite index. The
+// default index name is <table>_<column>.
+//
+// For example:
+//
+//	type User struct {
+//		ID    int64   `db:"id,primary_key,auto"`
+//		Email string  `db:"email,unique,size=128"`
+//		Group *Group  `db:"group,foreign_key=group_id,reference_key=id"`
+//	}
+func (d *DialectBuilder) DescribeEntity(entity interface{}) (*EntityTable, error) {
+	schema, err := schemaOf(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		table   = &EntityTable{Name: schema.table, PrimaryKey: schema.primary()}
+		indexes = map[string]*EntityIndex{}
+	)
+
+	for _, field := range schema.fields {
+		typ, err := field.columnType(d.dialect)
+		if err != nil {
+			return nil, err
+		}
+
+		column := &EntityColumn{
+			Name:     field.name,
+			Type:     typ,
+			Nullable: field.nullable && !field.has("not_null") && !field.has("primary_key"),
+			Unique:   field.has("unique"),
+		}
+
+		if field.has("auto") {
+			switch {
+			case field.integer():
+				column.AutoIncrement = true
+			case field.typ == timeType:
+				column.Default = "CURRENT_TIMESTAMP"
+			}
+		}
+
+		table.Columns = append(table.Columns, column)
+
+		for _, option := range []string{"index", "unique_index"} {
+			name, ok := field.options[option]
+			if !ok {
+				continue
+			}
+
+			if name == "" {
+				name = schema.table + "_" + field.name
+			}
+
+			index, ok := indexes[name]
+			if !ok {
+				index = &EntityIndex{Name: name}
+				indexes[name] = index
+				table.Indexes = append(table.Indexes, index)
+			}
+
+			index.Unique = index.Unique || option == "unique_index"
+			index.Columns = append(index.Columns, field.name)
+		}
+
+		if field.reference != nil {
+			fk := &EntityForeignKey{
+				Columns:    []string{field.name},
+				RefTable:   field.reference.table,
+				RefColumns: []string{field.reference.column},
+			}
+
+			if action, ok := field.options["on_delete"]; ok {
+				fk.OnDelete = strings.ToUpper(strings.ReplaceAll(action, "_", " "))
+			}
+
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+	}
+
+	return table, nil
+}
+
+// CreateTableFrom creates a TableBuilder for the configured dialect from the
+// `db` tags of the given entity. See DescribeEntity for the supported tag
+// options.
+//
+//	Dialect(dialect.Postgres).
+//		CreateTableFrom(&User{}).
+//		IfNotExists()
+func (d *DialectBuilder) CreateTableFrom(entity interface{}) *TableBuilder {
+	entry, err := d.DescribeEntity(entity)
+	if err != nil {
+		b := d.CreateTable("")
+		b.AddError(err)
+		return b
+	}
+
+	var (
+		table  = d.CreateTable(entry.Name)
+		inline = false
+	)
+
+	for _, field := range entry.Columns {
+		column := d.Column(field.Name).Type(field.Type)
+
+		if !field.Nullable {
+			column.Attr("NOT NULL")
+		}
+
+		switch {
+		case field.AutoIncrement && dialect.FamilyOf(d.dialect) == dialect.SQLite && len(entry.PrimaryKey) == 1 && entry.PrimaryKey[0] == field.Name:
+			// SQLite supports AUTOINCREMENT only for INTEGER PRIMARY KEY columns
+			column.Type("integer").Attr("PRIMARY KEY AUTOINCREMENT")
+			inline = true
+		case field.AutoIncrement && dialect.FamilyOf(d.dialect) == dialect.Postgres:
+			column.Attr("GENERATED BY DEFAULT AS IDENTITY")
+		case field.AutoIncrement && dialect.FamilyOf(d.dialect) == dialect.SQLServer:
+			column.Attr("IDENTITY")
+		case field.AutoIncrement:
+			column.Attr("AUTO_INCREMENT")
+		case field.Default != "":
+			column.Attr("DEFAULT " + field.Default)
+		}
+
+		if field.Unique {
+			column.Attr("UNIQUE")
+		}
+
+		table.Column(column)
+	}
+
+	if len(entry.PrimaryKey) > 0 && !inline {
+		table.PrimaryKey(entry.PrimaryKey...)
+	}
+
+	for _, key := range entry.ForeignKeys {
+		fk := ForeignKey().
+			Columns(key.Columns...).
+			Reference(Reference().Table(key.RefTable).Columns(key.RefColumns...))
+
+		if key.OnDelete != "" {
+			fk.OnDelete(key.OnDelete)
+		}
+
+		table.ForeignKeys(fk)
+	}
+
+	return table
+}
+
+// CreateIndexesFrom creates the IndexBuilders for the configured dialect from
+// the `db` tags of the given entity. See DescribeEntity for the supported tag
+// options.
+//
+//	Dialect(dialect.Postgres).
+//		CreateIndexesFrom(&User{})
+func (d *DialectBuilder) CreateIndexesFrom(entity interface{}) []*IndexBuilder {
+	entry, err := d.DescribeEntity(entity)
+	if err != nil {
+		b := d.CreateIndex("")
+		b.AddError(err)
+		return []*IndexBuilder{b}
+	}
+
+	indexes := []*IndexBuilder{}
+
+	for _, index := range entry.Indexes {
+		b := d.CreateIndex(index.Name).
+			Table(entry.Name).
+			Columns(index.Columns...)
+
+		if index.Unique {
+			b.Unique()
+		}
+
+		indexes = append(indexes, b)
+	}
+
+	return indexes
+}
+
+// entitySchema represents the table of an entity.
+type entitySchema struct {
+	table  string
+	fields []*entityField
+}
+
+func (s *entitySchema) primary() []string {
+	columns := []string{}
+
+	for _, field := range s.fields {
+		if field.has("primary_key") {
+			columns = append(columns, field.name)
+		}
+	}
+
+	return columns
+}
+
+// entityField represents a column of an entity.
+type entityField struct {
+	name      string
+	typ       reflect.Type
+	nullable  bool
+	options   map[string]string
+	reference *entityReference
+}
+
+// entityReference represents the referenced column of a foreign key.
+type entityReference struct {
+	table  string
+	column string
+}
+
+func (f *entityField) has(option string) bool {
+	_, ok := f.options[option]
+	return ok
+}
+
+func (f *entityField) integer() bool {
+	switch f.typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// columnType returns the column type of the field for the given dialect.
+func (f *entityField) columnType(name string) (string, error) {
+	if typ, ok := f.options["type"]; ok {
+		return typ, nil
+	}
+
+	typ, ok := f.portableType()
+	if !ok {
+		return "", fmt.Errorf("sql: unsupported type %v of column %q", f.typ, f.name)
+	}
+
+	var size int
+
+	if value, ok := f.options["size"]; ok && typ == dialect.TypeString {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("sql: invalid size %q of column %q", value, f.name)
+		}
+
+		size = n
+	}
+
+	spec, ok := dialect.Lookup(name)
+	if !ok {
+		// the builder renders the unknown dialects as MySQL
+		spec = dialect.MustLookup(dialect.MySQL)
+	}
+
+	if column, ok := spec.ColumnType(typ, size); ok {
+		return column, nil
+	}
+
+	return "", fmt.Errorf("sql: unsupported type %v of column %q", f.typ, f.name)
+}
+
+// portableType returns the portable type of the field that is mapped to the
+// column type by the dialects.
+func (f *entityField) portableType() (dialect.Type, bool) {
+	switch f.typ {
+	case timeType:
+		return dialect.TypeTime, true
+	case jsonType:
+		return dialect.TypeJSON, true
+	}
+
+	switch f.typ.Kind() {
+	case reflect.Bool:
+		return dialect.TypeBool, true
+	case reflect.Int8:
+		return dialect.TypeInt8, true
+	case reflect.Int16:
+		return dialect.TypeInt16, true
+	case reflect.Int32:
+		return dialect.TypeInt32, true
+	case reflect.Int, reflect.Int64:
+		return dialect.TypeInt64, true
+	case reflect.Uint8:
+		return dialect.TypeUint8, true
+	case reflect.Uint16:
+		return dialect.TypeUint16, true
+	case reflect.Uint32:
+		return dialect.TypeUint32, true
+	case reflect.Uint, reflect.Uint64:
+		return dialect.TypeUint64, true
+	case reflect.Float32:
+		return dialect.TypeFloat32, true
+	case reflect.Float64:
+		return dialect.TypeFloat64, true
+	case reflect.String:
+		return dialect.TypeString, true
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.Uint8 {
+			return dialect.TypeBytes, true
+		}
+	}
+
+	return 0, false
+}
+
+// schemaOf returns the schema of the given entity.
+func schemaOf(entity interface{}) (*entitySchema, error) {
+	typ := reflect.TypeOf(entity)
+
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sql: invalid type %v. expected struct as an argument", typ)
+	}
+
+	schema := &entitySchema{
+		table: tableOf(typ),
+	}
+
+	var walk func(children []*reflectx.FieldInfo) error
+
+	walk = func(children []*reflectx.FieldInfo) error {
+		for _, info := range children {
+			if info == nil {
+				continue
+			}
+
+			if info.Embedded && info.Field.Tag.Get("db") == "" {
+				if err := walk(info.Children); err != nil {
+					return err
+				}
+				continue
+			}
+
+			field, err := fieldOf(info)
+			if err != nil {
+				return err
+			}
+
+			schema.fields = append(schema.fields, field)
+		}
+
+		return nil
+	}
+
+	if err := walk(schemaMapper.TypeMap(typ).Tree.Children); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// fieldOf returns the column of the given struct field.
+func fieldOf(info *reflectx.FieldInfo) (*entityField, error) {
+	field := &entityField{
+		name:    info.Name,
+		typ:     info.Field.Type,
+		options: info.Options,
+	}
+
+	if field.typ.Kind() == reflect.Ptr {
+		field.typ = field.typ.Elem()
+		field.nullable = true
+	}
+
+	// sql.NullString, sql.NullInt64, sql.Null[T] and etc.
+	if field.typ.Kind() == reflect.Struct && field.typ.NumField() == 2 {
+		if valid := field.typ.Field(1); valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool {
+			field.typ = field.typ.Field(0).Type
+			field.nullable = true
+		}
+	}
+
+	if name, ok := info.Options["foreign_key"]; ok {
+		key, ok := info.Options["reference_key"]
+		if !ok {
+			return nil, fmt.Errorf("sql: column %q does not have a reference_key", info.Name)
+		}
+
+		if field.typ.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("sql: column %q is not a struct", info.Name)
+		}
+
+		ref := schemaMapper.TypeMap(field.typ).GetByPath(key)
+		if ref == nil {
+			return nil, fmt.Errorf("sql: reference_key %q of column %q not found", key, info.Name)
+		}
+
+		field.name = name
+		field.reference = &entityReference{
+			table:  tableOf(field.typ),
+			column: key,
+		}
+
+		column, err := fieldOf(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		field.typ = column.typ
+		field.options = make(map[string]string, len(info.Options))
+
+		for k, v := range info.Options {
+			field.options[k] = v
+		}
+
+		// the foreign key has the same type as the referenced column
+		for _, option := range []string{"type", "size"} {
+			if _, ok := field.options[option]; !ok {
+				if value, ok := column.options[option]; ok {
+					field.options[option] = value
+				}
+			}
+		}
+
+		return field, nil
+	}
+
+	// the nested structs are mapped to many columns by reflectx
+	if _, ok := field.options["type"]; !ok && field.typ.Kind() == reflect.Struct && field.typ != timeType {
+		return nil, fmt.Errorf("sql: column %q is a nested struct %v. add the foreign_key option or ignore it with db:\"-\"", info.Name, field.typ)
+	}
+
+	return field, nil
+}
+
+// tableOf returns the table name of the given entity type.
+func tableOf(typ reflect.Type) string {
+	if namer, ok := reflect.New(typ).Interface().(TableNamer); ok {
+		return namer.TableName()
+	}
+
+	name := inflect.Underscore(typ.Name())
+	return strings.ToLower(inflect.Pluralize(name))
+}
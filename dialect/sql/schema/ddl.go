@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"strings"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// ddl renders the statements that change the schema objects in a specific
+// dialect. The dialect is the family of the registered dialect, so the
+// dialects that follow PostgreSQL or MySQL are rendered as them.
+type ddl struct {
+	dialect string
+	builder *sql.DialectBuilder
+}
+
+func newDDL(name string) *ddl {
+	return &ddl{
+		dialect: dialect.FamilyOf(name),
+		builder: sql.Dialect(name),
+	}
+}
+
+func (d *ddl) postgres() bool {
+	return d.dialect == dialect.Postgres
+}
+
+// serial reports whether the column is the auto incremented primary key of a
+// SQLite table. Such column must be declared as INTEGER PRIMARY KEY.
+func (d *ddl) serial(table *Table, column *Column) bool {
+	return d.dialect == dialect.SQLite && column.AutoIncrement &&
+		len(table.PrimaryKey) == 1 && table.PrimaryKey[0] == column.Name
+}
+
+// typeOf returns the normalized type of the column as it's created by the
+// database.
+func (d *ddl) typeOf(table *Table, column *Column) string {
+	if d.serial(table, column) {
+		return "integer"
+	}
+
+	return normalize(d.dialect, column.Type)
+}
+
+// column returns the definition of the column.
+func (d *ddl) column(table *Table, column *Column) *sql.ColumnBuilder {
+	b := d.builder.Column(column.Name).Type(column.Type)
+
+	if !column.Nullable {
+		b.Attr("NOT NULL")
+	}
+
+	switch {
+	case d.serial(table, column):
+		b.Type("integer").Attr("PRIMARY KEY AUTOINCREMENT")
+	case column.AutoIncrement && d.postgres():
+		b.Attr("GENERATED BY DEFAULT AS IDENTITY")
+	case column.AutoIncrement && d.dialect != dialect.SQLite:
+		b.Attr("AUTO_INCREMENT")
+	case column.Default != nil:
+		b.Attr("DEFAULT " + *column.Default)
+	}
+
+	return b
+}
+
+// createTable returns the CREATE TABLE statement of the table. The foreign
+// keys are inlined only in SQLite that cannot add them later.
+func (d *ddl) createTable(table *Table) sql.Querier {
+	var (
+		b      = d.builder.CreateTable(table.Name)
+		inline = false
+	)
+
+	for _, column := range table.Columns {
+		inline = inline || d.serial(table, column)
+		b.Column(d.column(table, column))
+	}
+
+	if len(table.PrimaryKey) > 0 && !inline {
+		b.PrimaryKey(table.PrimaryKey...)
+	}
+
+	if d.dialect == dialect.SQLite {
+		for _, key := range table.ForeignKeys {
+			b.ForeignKeys(d.foreignKey(key))
+		}
+	}
+
+	return b
+}
+
+// dropTable returns the DROP TABLE statement of the table.
+func (d *ddl) dropTable(table *Table) sql.Querier {
+	return d.builder.DropTable(table.Name)
+}
+
+func (d *ddl) addColumn(table *Table, column *Column) sql.Querier {
+	return d.builder.AlterTable(table.Name).AddColumn(d.column(table, column))
+}
+
+func (d *ddl) dropColumn(table *Table, column *Column) sql.Querier {
+	return d.builder.AlterTable(table.Name).DropColumn(d.builder.Column(column.Name))
+}
+
+// modifyColumn changes the type and the nullability of the column. MySQL
+// redefines the whole column, while PostgreSQL alters each property.
+func (d *ddl) modifyColumn(table *Table, from, to *Column) sql.Querier {
+	b := d.builder.AlterTable(table.Name)
+
+	if !d.postgres() {
+		return b.ModifyColumn(d.column(table, to))
+	}
+
+	if normalize(d.dialect, from.Type) != normalize(d.dialect, to.Type) {
+		b.ModifyColumn(d.builder.Column(to.Name).Type(to.Type))
+	}
+
+	if from.Nullable != to.Nullable {
+		action := "SET NOT NULL"
+		if to.Nullable {
+			action = "DROP NOT NULL"
+		}
+
+		b.Queries = append(b.Queries, sql.Raw("ALTER COLUMN "+b.Quote(to.Name)+" "+action))
+	}
+
+	return b
+}
+
+func (d *ddl) createIndex(table *Table, index *Index) sql.Querier {
+	b := d.builder.CreateIndex(index.Name).
+		Table(table.Name).
+		Columns(index.Columns...)
+
+	if index.Unique {
+		b.Unique()
+	}
+
+	return b
+}
+
+func (d *ddl) dropIndex(table *Table, index *Index) sql.Querier {
+	b := d.builder.DropIndex(index.Name)
+
+	if d.dialect == dialect.MySQL {
+		b.Table(table.Name)
+	}
+
+	return b
+}
+
+func (d *ddl) foreignKey(key *ForeignKey) *sql.ForeignKeyBuilder {
+	b := sql.ForeignKey(key.Name).
+		Columns(key.Columns...).
+		Reference(sql.Reference().Table(key.RefTable).Columns(key.RefColumns...))
+
+	if key.OnDelete != "" {
+		b.OnDelete(key.OnDelete)
+	}
+
+	if key.OnUpdate != "" {
+		b.OnUpdate(key.OnUpdate)
+	}
+
+	return b
+}
+
+func (d *ddl) addForeignKey(table *Table, key *ForeignKey) sql.Querier {
+	return d.builder.AlterTable(table.Name).AddForeignKey(d.foreignKey(key))
+}
+
+func (d *ddl) dropForeignKey(table *Table, key *ForeignKey) sql.Querier {
+	b := d.builder.AlterTable(table.Name)
+
+	if d.dialect == dialect.MySQL {
+		return b.DropForeignKey(key.Name)
+	}
+
+	return b.DropConstraint(key.Name)
+}
+
+// action returns the canonical form of the referential action. The missing
+// action is the default NO ACTION that behaves like RESTRICT.
+func action(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	switch value {
+	case "", "RESTRICT":
+		return "NO ACTION"
+	default:
+		return value
+	}
+}
@@ -0,0 +1,471 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/inflect"
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/prana/sqlmigr"
+)
+
+// DiffOptions holds the options to be used in Diff.
+type DiffOptions struct {
+	// Dialect is the dialect of the rendered statements.
+	Dialect string
+	// AllowDestructive allows the changes that may lose data, like dropping
+	// columns or narrowing their types.
+	AllowDestructive bool
+}
+
+// Change represents a single change of the schema.
+type Change struct {
+	// Description describes the change (e.g. add column users.name).
+	Description string
+	// Destructive reports whether the change may lose data.
+	Destructive bool
+	// Queries are the statements that apply the change.
+	Queries []sql.Querier
+	// Revert are the statements that revert the change.
+	Revert []sql.Querier
+	// the rendered statements (the builders can be rendered only once)
+	up   []string
+	down []string
+}
+
+// Plan represents the ordered changes that migrate the current schema to the
+// desired one.
+type Plan struct {
+	// Changes are the changes in the order of execution.
+	Changes []*Change
+}
+
+// Destructive returns the changes that may lose data.
+func (p *Plan) Destructive() []*Change {
+	result := []*Change{}
+
+	for _, change := range p.Changes {
+		if change.Destructive {
+			result = append(result, change)
+		}
+	}
+
+	return result
+}
+
+// Statements returns the SQL statements that apply the plan.
+func (p *Plan) Statements() []string {
+	statements := []string{}
+
+	for _, change := range p.Changes {
+		statements = append(statements, change.up...)
+	}
+
+	return statements
+}
+
+// RevertStatements returns the SQL statements that revert the plan. The
+// changes are reverted in reverse order.
+func (p *Plan) RevertStatements() []string {
+	statements := []string{}
+
+	for i := len(p.Changes) - 1; i >= 0; i-- {
+		statements = append(statements, p.Changes[i].down...)
+	}
+
+	return statements
+}
+
+// WriteMigration writes the plan as a timestamped migration file with up and
+// down routines in the format consumed by Gateway.Migrate. It returns the
+// migration. The plan must not be empty.
+func (p *Plan) WriteMigration(storage sqlmigr.WriteFileSystem, name string) (*sqlmigr.Migration, error) {
+	if len(p.Changes) == 0 {
+		return nil, fmt.Errorf("schema: the migration %q does not have any changes", name)
+	}
+
+	var (
+		now      = time.Now().UTC()
+		filename = fmt.Sprintf("%s_%s.sql", now.Format("20060102150405"), inflect.Underscore(strings.ToLower(name)))
+	)
+
+	item, err := sqlmigr.Parse(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	item.CreatedAt = now
+
+	content := &sqlmigr.Content{
+		UpCommand:   p.script(p.Statements()),
+		DownCommand: p.script(p.RevertStatements()),
+	}
+
+	generator := &sqlmigr.Generator{
+		FileSystem: storage,
+	}
+
+	if err := generator.Write(item, content); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// script separates the statements with the separator recognized by the
+// migration runner.
+func (p *Plan) script(statements []string) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+
+	for i, statement := range statements {
+		if i > 0 {
+			fmt.Fprintln(buffer, "GO")
+		}
+
+		fmt.Fprintf(buffer, "%s;\n", statement)
+	}
+
+	return buffer
+}
+
+func render(name string, queries []sql.Querier) []string {
+	statements := make([]string, len(queries))
+
+	for i, query := range queries {
+		if builder, ok := query.(interface{ SetDialect(string) }); ok {
+			builder.SetDialect(name)
+		}
+
+		statements[i], _ = query.Query()
+	}
+
+	return statements
+}
+
+// DestructiveChangeError is returned by Diff when the plan contains changes
+// that may lose data and they were not allowed explicitly.
+type DestructiveChangeError struct {
+	// Changes are the destructive changes.
+	Changes []*Change
+}
+
+// Error implements the error interface.
+func (e *DestructiveChangeError) Error() string {
+	descriptions := make([]string, len(e.Changes))
+
+	for i, change := range e.Changes {
+		descriptions[i] = change.Description
+	}
+
+	return fmt.Sprintf("schema: destructive changes require explicit opt-in: %s", strings.Join(descriptions, ", "))
+}
+
+// the phases of the plan. The foreign keys and the indexes are dropped before
+// the columns are changed and created after.
+const (
+	phaseDropForeignKey = iota
+	phaseDropIndex
+	phaseCreateTable
+	phaseAlterColumn
+	phaseAddForeignKey
+	phaseCreateIndex
+	phaseCount
+)
+
+// Diff compares the current schema with the desired one and returns the plan
+// that migrates the database. The tables that are not in the desired schema
+// are kept. The default values are not compared.
+//
+// It returns a *DestructiveChangeError if the plan contains changes that may
+// lose data or fail on the existing rows (dropping columns, narrowing types,
+// making columns NOT NULL or adding NOT NULL columns without a default) and
+// they are not allowed by the options.
+func Diff(current, desired *Schema, opts *DiffOptions) (*Plan, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+
+	differ := &differ{
+		ddl:    newDDL(opts.Dialect),
+		phases: make([][]*Change, phaseCount),
+	}
+
+	for _, table := range desired.Tables {
+		if err := differ.table(current.Table(table.Name), table); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &Plan{}
+
+	for _, changes := range differ.phases {
+		for _, change := range changes {
+			change.up = render(opts.Dialect, change.Queries)
+			change.down = render(opts.Dialect, change.Revert)
+		}
+
+		plan.Changes = append(plan.Changes, changes...)
+	}
+
+	if changes := plan.Destructive(); len(changes) > 0 && !opts.AllowDestructive {
+		return nil, &DestructiveChangeError{Changes: changes}
+	}
+
+	return plan, nil
+}
+
+// differ collects the changes of the tables by phase.
+type differ struct {
+	ddl    *ddl
+	phases [][]*Change
+}
+
+func (d *differ) add(phase int, change *Change) {
+	d.phases[phase] = append(d.phases[phase], change)
+}
+
+func (d *differ) table(current, desired *Table) error {
+	if current == nil {
+		d.create(desired)
+		return nil
+	}
+
+	if !slices.Equal(current.PrimaryKey, desired.PrimaryKey) {
+		return fmt.Errorf("schema: changing the primary key of table %q is not supported", desired.Name)
+	}
+
+	if err := d.columns(current, desired); err != nil {
+		return err
+	}
+
+	d.indexes(current, desired)
+
+	return d.foreignKeys(current, desired)
+}
+
+func (d *differ) create(table *Table) {
+	d.add(phaseCreateTable, &Change{
+		Description: "create table " + table.Name,
+		Queries:     []sql.Querier{d.ddl.createTable(table)},
+		Revert:      []sql.Querier{d.ddl.dropTable(table)},
+	})
+
+	// SQLite creates the foreign keys with the table
+	if d.ddl.dialect != dialect.SQLite {
+		for _, key := range table.ForeignKeys {
+			d.add(phaseAddForeignKey, &Change{
+				Description: "add foreign key " + d.describeForeignKey(table, key),
+				Queries:     []sql.Querier{d.ddl.addForeignKey(table, key)},
+				Revert:      []sql.Querier{d.ddl.dropForeignKey(table, key)},
+			})
+		}
+	}
+
+	for _, index := range table.Indexes {
+		d.add(phaseCreateIndex, &Change{
+			Description: "create index " + index.Name,
+			Queries:     []sql.Querier{d.ddl.createIndex(table, index)},
+			Revert:      []sql.Querier{d.ddl.dropIndex(table, index)},
+		})
+	}
+}
+
+func (d *differ) columns(current, desired *Table) error {
+	for _, column := range desired.Columns {
+		name := desired.Name + "." + column.Name
+
+		existing := current.Column(column.Name)
+		if existing == nil {
+			change := &Change{
+				Description: "add column " + name,
+				Queries:     []sql.Querier{d.ddl.addColumn(desired, column)},
+				Revert:      []sql.Querier{d.ddl.dropColumn(desired, column)},
+			}
+
+			// the existing rows do not have a value for the column
+			if !column.Nullable && column.Default == nil && !column.AutoIncrement {
+				change.Description += " (NOT NULL without default)"
+				change.Destructive = true
+			}
+
+			d.add(phaseAlterColumn, change)
+			continue
+		}
+
+		var (
+			from = d.ddl.typeOf(current, existing)
+			to   = d.ddl.typeOf(desired, column)
+		)
+
+		if from == to && existing.Nullable == column.Nullable {
+			continue
+		}
+
+		if d.ddl.dialect == dialect.SQLite {
+			return fmt.Errorf("schema: cannot modify column %q in %s", name, dialect.SQLite)
+		}
+
+		change := &Change{
+			Description: fmt.Sprintf("modify column %s (%s to %s)", name, describeColumn(from, existing), describeColumn(to, column)),
+			Destructive: narrowing(from, to) || (existing.Nullable && !column.Nullable),
+			Queries:     []sql.Querier{d.ddl.modifyColumn(desired, existing, column)},
+			Revert:      []sql.Querier{d.ddl.modifyColumn(current, column, existing)},
+		}
+
+		d.add(phaseAlterColumn, change)
+	}
+
+	for _, column := range current.Columns {
+		if desired.Column(column.Name) != nil {
+			continue
+		}
+
+		d.add(phaseAlterColumn, &Change{
+			Description: "drop column " + desired.Name + "." + column.Name,
+			Destructive: true,
+			Queries:     []sql.Querier{d.ddl.dropColumn(current, column)},
+			Revert:      []sql.Querier{d.ddl.addColumn(current, column)},
+		})
+	}
+
+	return nil
+}
+
+// indexes compares the indexes by their columns and uniqueness. The names of
+// the indexes are not compared, because they are generated by the database
+// for some constraints.
+func (d *differ) indexes(current, desired *Table) {
+	for _, index := range desired.Indexes {
+		if indexIn(current.Indexes, index) {
+			continue
+		}
+
+		d.add(phaseCreateIndex, &Change{
+			Description: "create index " + index.Name,
+			Queries:     []sql.Querier{d.ddl.createIndex(desired, index)},
+			Revert:      []sql.Querier{d.ddl.dropIndex(desired, index)},
+		})
+	}
+
+	for _, index := range current.Indexes {
+		switch {
+		case indexIn(desired.Indexes, index):
+			continue
+		case strings.HasPrefix(index.Name, "sqlite_autoindex_"):
+			// the indexes of the UNIQUE constraints cannot be dropped in SQLite
+			continue
+		case d.backs(current, index):
+			// MySQL creates the indexes of the foreign keys
+			continue
+		}
+
+		d.add(phaseDropIndex, &Change{
+			Description: "drop index " + index.Name,
+			Queries:     []sql.Querier{d.ddl.dropIndex(current, index)},
+			Revert:      []sql.Querier{d.ddl.createIndex(current, index)},
+		})
+	}
+}
+
+// backs reports whether the index is created for a foreign key of the table.
+func (d *differ) backs(table *Table, index *Index) bool {
+	if index.Unique {
+		return false
+	}
+
+	for _, key := range table.ForeignKeys {
+		if slices.Equal(key.Columns, index.Columns) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// foreignKeys compares the foreign keys by their columns, references and
+// actions. SQLite cannot alter the foreign keys of existing tables.
+func (d *differ) foreignKeys(current, desired *Table) error {
+	for _, key := range desired.ForeignKeys {
+		if foreignKeyIn(current.ForeignKeys, key) {
+			continue
+		}
+
+		if d.ddl.dialect == dialect.SQLite {
+			return fmt.Errorf("schema: cannot add foreign key %s in %s", d.describeForeignKey(desired, key), dialect.SQLite)
+		}
+
+		d.add(phaseAddForeignKey, &Change{
+			Description: "add foreign key " + d.describeForeignKey(desired, key),
+			Queries:     []sql.Querier{d.ddl.addForeignKey(desired, key)},
+			Revert:      []sql.Querier{d.ddl.dropForeignKey(desired, key)},
+		})
+	}
+
+	for _, key := range current.ForeignKeys {
+		if foreignKeyIn(desired.ForeignKeys, key) {
+			continue
+		}
+
+		if d.ddl.dialect == dialect.SQLite {
+			return fmt.Errorf("schema: cannot drop foreign key %s in %s", d.describeForeignKey(current, key), dialect.SQLite)
+		}
+
+		d.add(phaseDropForeignKey, &Change{
+			Description: "drop foreign key " + d.describeForeignKey(current, key),
+			Queries:     []sql.Querier{d.ddl.dropForeignKey(current, key)},
+			Revert:      []sql.Querier{d.ddl.addForeignKey(current, key)},
+		})
+	}
+
+	return nil
+}
+
+func (d *differ) describeForeignKey(table *Table, key *ForeignKey) string {
+	return fmt.Sprintf("%s(%s) references %s(%s)",
+		table.Name, strings.Join(key.Columns, ", "),
+		key.RefTable, strings.Join(key.RefColumns, ", "),
+	)
+}
+
+func describeColumn(typ string, column *Column) string {
+	if column.Nullable {
+		return typ + " NULL"
+	}
+
+	return typ + " NOT NULL"
+}
+
+func indexIn(indexes []*Index, index *Index) bool {
+	for _, item := range indexes {
+		if item.Unique == index.Unique && slices.Equal(item.Columns, index.Columns) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func foreignKeyIn(keys []*ForeignKey, key *ForeignKey) bool {
+	for _, item := range keys {
+		switch {
+		case item.RefTable != key.RefTable:
+			continue
+		case !slices.Equal(item.Columns, key.Columns):
+			continue
+		case !slices.Equal(item.RefColumns, key.RefColumns):
+			continue
+		case action(item.OnDelete) != action(key.OnDelete):
+			continue
+		case action(item.OnUpdate) != action(key.OnUpdate):
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
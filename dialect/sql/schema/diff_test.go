@@ -0,0 +1,351 @@
+package schema_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/orm/dialect/sql/schema"
+	"github.com/phogolabs/prana/storage"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type DiffGroup struct {
+	ID   int64  `db:"id,primary_key,auto"`
+	Name string `db:"name,size=64,unique"`
+}
+
+func (DiffGroup) TableName() string {
+	return "groups"
+}
+
+type DiffUser struct {
+	ID    int64      `db:"id,primary_key,auto"`
+	Email string     `db:"email,size=128,unique_index"`
+	Group *DiffGroup `db:"group,foreign_key=group_id,reference_key=id,on_delete=cascade"`
+}
+
+func (DiffUser) TableName() string {
+	return "users"
+}
+
+type DiffUserV2 struct {
+	ID       int64      `db:"id,primary_key,auto"`
+	Email    string     `db:"email,size=128,unique_index"`
+	Nickname *string    `db:"nickname,size=64,index"`
+	Group    *DiffGroup `db:"group,foreign_key=group_id,reference_key=id,on_delete=cascade"`
+}
+
+func (DiffUserV2) TableName() string {
+	return "users"
+}
+
+var _ = Describe("Diff", func() {
+	Describe("SQLite", func() {
+		var (
+			ctx       context.Context
+			driver    *sql.Driver
+			inspector *schema.Inspector
+		)
+
+		inspect := func() *schema.Schema {
+			current, err := inspector.Inspect(ctx)
+			Expect(err).To(BeNil())
+			return current
+		}
+
+		apply := func(statements []string) {
+			for _, query := range statements {
+				Expect(driver.Exec(ctx, query, []interface{}{}, nil)).To(Succeed())
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+
+			ctx = context.TODO()
+
+			driver, err = sql.Open("sqlite3", "file:diff.db?cache=shared&mode=memory")
+			Expect(err).To(BeNil())
+
+			inspector, err = schema.NewInspector(driver)
+			Expect(err).To(BeNil())
+
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUser{})
+			Expect(err).To(BeNil())
+
+			plan, err := schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(err).To(BeNil())
+			apply(plan.Statements())
+		})
+
+		AfterEach(func() {
+			apply([]string{"DROP TABLE IF EXISTS users", "DROP TABLE IF EXISTS groups"})
+			Expect(driver.Close()).To(Succeed())
+		})
+
+		It("does not have changes when the schema is up to date", func() {
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUser{})
+			Expect(err).To(BeNil())
+
+			plan, err := schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(err).To(BeNil())
+			Expect(plan.Changes).To(BeEmpty())
+		})
+
+		It("adds the new columns and indexes", func() {
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUserV2{})
+			Expect(err).To(BeNil())
+
+			plan, err := schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(err).To(BeNil())
+			Expect(plan.Destructive()).To(BeEmpty())
+			Expect(plan.Statements()).To(Equal([]string{
+				"ALTER TABLE `users` ADD COLUMN `nickname` varchar(64)",
+				"CREATE INDEX `users_nickname` ON `users`(`nickname`)",
+			}))
+			Expect(plan.RevertStatements()).To(Equal([]string{
+				"DROP INDEX `users_nickname`",
+				"ALTER TABLE `users` DROP COLUMN `nickname`",
+			}))
+
+			apply(plan.Statements())
+
+			table, err := inspector.InspectTable(ctx, "users")
+			Expect(err).To(BeNil())
+			Expect(table.Column("nickname")).NotTo(BeNil())
+			Expect(table.Index("users_nickname")).NotTo(BeNil())
+
+			apply(plan.RevertStatements())
+
+			table, err = inspector.InspectTable(ctx, "users")
+			Expect(err).To(BeNil())
+			Expect(table.Column("nickname")).To(BeNil())
+		})
+
+		It("requires an explicit opt-in to drop columns", func() {
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUserV2{})
+			Expect(err).To(BeNil())
+
+			plan, err := schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(err).To(BeNil())
+			apply(plan.Statements())
+
+			desired, err = schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUser{})
+			Expect(err).To(BeNil())
+
+			plan, err = schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(plan).To(BeNil())
+			Expect(err).To(BeAssignableToTypeOf(&schema.DestructiveChangeError{}))
+			Expect(err).To(MatchError("schema: destructive changes require explicit opt-in: drop column users.nickname"))
+
+			plan, err = schema.Diff(inspect(), desired, &schema.DiffOptions{
+				Dialect:          dialect.SQLite,
+				AllowDestructive: true,
+			})
+			Expect(err).To(BeNil())
+			Expect(plan.Statements()).To(Equal([]string{
+				"DROP INDEX `users_nickname`",
+				"ALTER TABLE `users` DROP COLUMN `nickname`",
+			}))
+
+			apply(plan.Statements())
+
+			table, err := inspector.InspectTable(ctx, "users")
+			Expect(err).To(BeNil())
+			Expect(table.Column("nickname")).To(BeNil())
+		})
+
+		It("requires an explicit opt-in to add NOT NULL columns without a default", func() {
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUser{})
+			Expect(err).To(BeNil())
+
+			users := desired.Table("users")
+			users.Columns = append(users.Columns, &schema.Column{Name: "age", Type: "integer"})
+
+			plan, err := schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(plan).To(BeNil())
+			Expect(err).To(MatchError("schema: destructive changes require explicit opt-in: add column users.age (NOT NULL without default)"))
+
+			value := "0"
+			users.Column("age").Default = &value
+
+			plan, err = schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(err).To(BeNil())
+			Expect(plan.Destructive()).To(BeEmpty())
+		})
+
+		It("returns an error when a column is modified", func() {
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{}, &DiffUser{})
+			Expect(err).To(BeNil())
+			desired.Table("users").Column("email").Type = "text"
+
+			plan, err := schema.Diff(inspect(), desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(plan).To(BeNil())
+			Expect(err).To(MatchError(`schema: cannot modify column "users.email" in sqlite3`))
+		})
+	})
+
+	Describe("Golden", func() {
+		var current *schema.Schema
+
+		BeforeEach(func() {
+			current = &schema.Schema{
+				Tables: []*schema.Table{
+					{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: "bigint", AutoIncrement: true},
+							{Name: "email", Type: "varchar(255)"},
+							{Name: "name", Type: "varchar(255)", Nullable: true},
+							{Name: "age", Type: "bigint"},
+							{Name: "group_id", Type: "bigint", Nullable: true},
+						},
+						PrimaryKey: []string{"id"},
+						Indexes: []*schema.Index{
+							{Name: "users_email", Unique: true, Columns: []string{"email"}},
+							{Name: "users_name", Columns: []string{"name"}},
+						},
+						ForeignKeys: []*schema.ForeignKey{
+							{Name: "users_ibfk_1", Columns: []string{"group_id"}, RefTable: "groups", RefColumns: []string{"id"}},
+						},
+					},
+				},
+			}
+		})
+
+		desired := func(typ string) *schema.Schema {
+			return &schema.Schema{
+				Tables: []*schema.Table{
+					{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: "bigint", AutoIncrement: true},
+							{Name: "email", Type: "varchar(255)"},
+							{Name: "name", Type: "varchar(255)"},
+							{Name: "age", Type: typ},
+							{Name: "group_id", Type: "bigint", Nullable: true},
+						},
+						PrimaryKey: []string{"id"},
+						Indexes: []*schema.Index{
+							{Name: "users_email_key", Unique: true, Columns: []string{"email"}},
+						},
+						ForeignKeys: []*schema.ForeignKey{
+							{Name: "users_group_id_fkey", Columns: []string{"group_id"}, RefTable: "groups", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+						},
+					},
+					{
+						Name: "groups",
+						Columns: []*schema.Column{
+							{Name: "id", Type: "bigint", AutoIncrement: true},
+						},
+						PrimaryKey: []string{"id"},
+					},
+				},
+			}
+		}
+
+		It("renders the MySQL statements", func() {
+			plan, err := schema.Diff(current, desired("int"), &schema.DiffOptions{
+				Dialect:          dialect.MySQL,
+				AllowDestructive: true,
+			})
+			Expect(err).To(BeNil())
+			Expect(plan.Statements()).To(Equal([]string{
+				"ALTER TABLE `users` DROP FOREIGN KEY `users_ibfk_1`",
+				"DROP INDEX `users_name` ON `users`",
+				"CREATE TABLE `groups`(`id` bigint NOT NULL AUTO_INCREMENT, PRIMARY KEY(`id`))",
+				"ALTER TABLE `users` MODIFY COLUMN `name` varchar(255) NOT NULL",
+				"ALTER TABLE `users` MODIFY COLUMN `age` int NOT NULL",
+				"ALTER TABLE `users` ADD CONSTRAINT `users_group_id_fkey` FOREIGN KEY(`group_id`) REFERENCES `groups`(`id`) ON DELETE CASCADE",
+			}))
+			Expect(plan.RevertStatements()).To(Equal([]string{
+				"ALTER TABLE `users` DROP FOREIGN KEY `users_group_id_fkey`",
+				"ALTER TABLE `users` MODIFY COLUMN `age` bigint NOT NULL",
+				"ALTER TABLE `users` MODIFY COLUMN `name` varchar(255)",
+				"DROP TABLE `groups`",
+				"CREATE INDEX `users_name` ON `users`(`name`)",
+				"ALTER TABLE `users` ADD CONSTRAINT `users_ibfk_1` FOREIGN KEY(`group_id`) REFERENCES `groups`(`id`)",
+			}))
+
+			descriptions := []string{}
+			for _, change := range plan.Destructive() {
+				descriptions = append(descriptions, change.Description)
+			}
+
+			Expect(descriptions).To(Equal([]string{
+				"modify column users.name (varchar(255) NULL to varchar(255) NOT NULL)",
+				"modify column users.age (bigint NOT NULL to int NOT NULL)",
+			}))
+		})
+
+		It("renders the PostgreSQL statements", func() {
+			current.Tables[0].Columns[1].Type = "character varying(255)"
+			current.Tables[0].Columns[3].Type = "integer"
+
+			plan, err := schema.Diff(current, desired("bigint"), &schema.DiffOptions{
+				Dialect:          dialect.Postgres,
+				AllowDestructive: true,
+			})
+			Expect(err).To(BeNil())
+			Expect(plan.Statements()).To(Equal([]string{
+				`ALTER TABLE "users" DROP CONSTRAINT "users_ibfk_1"`,
+				`DROP INDEX "users_name"`,
+				`CREATE TABLE "groups"("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, PRIMARY KEY("id"))`,
+				`ALTER TABLE "users" ALTER COLUMN "name" SET NOT NULL`,
+				`ALTER TABLE "users" ALTER COLUMN "age" TYPE bigint`,
+				`ALTER TABLE "users" ADD CONSTRAINT "users_group_id_fkey" FOREIGN KEY("group_id") REFERENCES "groups"("id") ON DELETE CASCADE`,
+			}))
+			Expect(plan.Destructive()).To(HaveLen(1))
+		})
+
+		It("flags the narrowing changes", func() {
+			plan, err := schema.Diff(current, desired("smallint"), &schema.DiffOptions{Dialect: dialect.MySQL})
+			Expect(plan).To(BeNil())
+			Expect(err).To(MatchError(ContainSubstring("modify column users.age (bigint NOT NULL to smallint NOT NULL)")))
+		})
+	})
+
+	Describe("Plan", func() {
+		It("writes the migration file", func() {
+			dir, err := os.MkdirTemp("", "orm")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(dir)
+
+			desired, err := schema.FromEntities(dialect.SQLite, &DiffGroup{})
+			Expect(err).To(BeNil())
+
+			plan, err := schema.Diff(&schema.Schema{}, desired, &schema.DiffOptions{Dialect: dialect.SQLite})
+			Expect(err).To(BeNil())
+
+			migration, err := plan.WriteMigration(storage.New(dir), "CreateGroups")
+			Expect(err).To(BeNil())
+			Expect(migration.Description).To(Equal("creategroups"))
+
+			data, err := os.ReadFile(filepath.Join(dir, migration.Filenames()[0]))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("-- name: up\n" +
+				"CREATE TABLE `groups`(`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, `name` varchar(64) NOT NULL);\n" +
+				"GO\n" +
+				"CREATE UNIQUE INDEX `groups_name_key` ON `groups`(`name`);\n" +
+				"-- name: down\n" +
+				"DROP INDEX `groups_name_key`;\n" +
+				"GO\n" +
+				"DROP TABLE `groups`;\n"))
+		})
+
+		It("returns an error when the plan is empty", func() {
+			plan, err := schema.Diff(&schema.Schema{}, &schema.Schema{}, nil)
+			Expect(err).To(BeNil())
+
+			migration, err := plan.WriteMigration(storage.New(os.TempDir()), "empty")
+			Expect(migration).To(BeNil())
+			Expect(err).To(MatchError(`schema: the migration "empty" does not have any changes`))
+		})
+	})
+})
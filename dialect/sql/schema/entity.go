@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"strings"
+
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// FromEntities creates the desired schema of the given entities for the given
+// dialect. The tables are described by the `db` tags of the entities (see
+// sql.DialectBuilder.DescribeEntity). The unique columns are described as
+// unique indexes named <table>_<column>_key.
+func FromEntities(name string, entities ...interface{}) (*Schema, error) {
+	var (
+		builder = sql.Dialect(name)
+		schema  = &Schema{}
+	)
+
+	for _, entity := range entities {
+		entry, err := builder.DescribeEntity(entity)
+		if err != nil {
+			return nil, err
+		}
+
+		schema.Tables = append(schema.Tables, tableOf(entry))
+	}
+
+	return schema, nil
+}
+
+func tableOf(entry *sql.EntityTable) *Table {
+	table := &Table{
+		Name:       entry.Name,
+		PrimaryKey: entry.PrimaryKey,
+	}
+
+	for _, field := range entry.Columns {
+		column := &Column{
+			Name:          field.Name,
+			Type:          field.Type,
+			Nullable:      field.Nullable,
+			AutoIncrement: field.AutoIncrement,
+		}
+
+		if field.Default != "" {
+			value := field.Default
+			column.Default = &value
+		}
+
+		if field.Unique {
+			table.Indexes = append(table.Indexes, &Index{
+				Name:    entry.Name + "_" + field.Name + "_key",
+				Unique:  true,
+				Columns: []string{field.Name},
+			})
+		}
+
+		table.Columns = append(table.Columns, column)
+	}
+
+	for _, index := range entry.Indexes {
+		table.Indexes = append(table.Indexes, &Index{
+			Name:    index.Name,
+			Unique:  index.Unique,
+			Columns: index.Columns,
+		})
+	}
+
+	for _, key := range entry.ForeignKeys {
+		table.ForeignKeys = append(table.ForeignKeys, &ForeignKey{
+			Name:       entry.Name + "_" + strings.Join(key.Columns, "_") + "_fkey",
+			Columns:    key.Columns,
+			RefTable:   key.RefTable,
+			RefColumns: key.RefColumns,
+			OnDelete:   key.OnDelete,
+		})
+	}
+
+	return table
+}
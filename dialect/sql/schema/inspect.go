@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// Inspector reads the schema of a live database. It supports SQLite, MySQL
+// and PostgreSQL.
+type Inspector struct {
+	querier dialect.Querier
+	dialect inspector
+}
+
+// NewInspector creates a new inspector for the given driver.
+func NewInspector(driver dialect.Driver) (*Inspector, error) {
+	var engine inspector
+
+	switch dialect.FamilyOf(driver.Dialect()) {
+	case dialect.SQLite:
+		engine = &sqlite{}
+	case dialect.MySQL:
+		engine = &mysql{}
+	case dialect.Postgres:
+		engine = &postgres{}
+	default:
+		return nil, fmt.Errorf("schema: unsupported dialect %q", driver.Dialect())
+	}
+
+	inspector := &Inspector{
+		querier: driver,
+		dialect: engine,
+	}
+
+	return inspector, nil
+}
+
+// Inspect reads the tables of the current database (or schema in
+// PostgreSQL) with their columns, indexes and foreign keys.
+func (i *Inspector) Inspect(ctx context.Context) (*Schema, error) {
+	names, err := i.dialect.tables(ctx, i.querier)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+
+	for _, name := range names {
+		table, err := i.table(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		schema.Tables = append(schema.Tables, table)
+	}
+
+	return schema, nil
+}
+
+// InspectTable reads the table with the given name. It returns an error if
+// the table does not exist.
+func (i *Inspector) InspectTable(ctx context.Context, name string) (*Table, error) {
+	names, err := i.dialect.tables(ctx, i.querier)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range names {
+		if table == name {
+			return i.table(ctx, name)
+		}
+	}
+
+	return nil, fmt.Errorf("schema: table %q does not exist", name)
+}
+
+func (i *Inspector) table(ctx context.Context, name string) (*Table, error) {
+	table := &Table{Name: name}
+
+	if err := i.dialect.columns(ctx, i.querier, table); err != nil {
+		return nil, err
+	}
+
+	if err := i.dialect.indexes(ctx, i.querier, table); err != nil {
+		return nil, err
+	}
+
+	if err := i.dialect.foreignKeys(ctx, i.querier, table); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// inspector reads the schema objects of a specific dialect.
+type inspector interface {
+	// tables returns the names of the tables ordered by name.
+	tables(ctx context.Context, querier dialect.Querier) ([]string, error)
+	// columns reads the columns and the primary key of the table.
+	columns(ctx context.Context, querier dialect.Querier, table *Table) error
+	// indexes reads the indexes of the table.
+	indexes(ctx context.Context, querier dialect.Querier, table *Table) error
+	// foreignKeys reads the foreign keys of the table.
+	foreignKeys(ctx context.Context, querier dialect.Querier, table *Table) error
+}
+
+// query executes the query and calls the scan function for each row.
+func query(ctx context.Context, querier dialect.Querier, query string, args []interface{}, scan func(*sql.Rows) error) error {
+	rows := &sql.Rows{}
+
+	if err := querier.Query(ctx, query, args, rows); err != nil {
+		return err
+	}
+	// close the rows
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// nullable returns a pointer to the string or nil if it's null.
+func nullable(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.String
+}
+
+// foreignKeyOf returns the last foreign key if it has the given name.
+// Otherwise, it appends a new foreign key to the table.
+func foreignKeyOf(table *Table, name string) *ForeignKey {
+	if count := len(table.ForeignKeys); count > 0 {
+		if fk := table.ForeignKeys[count-1]; fk.Name == name {
+			return fk
+		}
+	}
+
+	fk := &ForeignKey{Name: name}
+	table.ForeignKeys = append(table.ForeignKeys, fk)
+	return fk
+}
+
+// indexOf returns the last index if it has the given name. Otherwise, it
+// appends a new index to the table.
+func indexOf(table *Table, name string) *Index {
+	if count := len(table.Indexes); count > 0 {
+		if index := table.Indexes[count-1]; index.Name == name {
+			return index
+		}
+	}
+
+	index := &Index{Name: name}
+	table.Indexes = append(table.Indexes, index)
+	return index
+}
@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"context"
+	"strings"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// mysql inspects the schema of the current database using the
+// information_schema.
+type mysql struct{}
+
+func (mysql) tables(ctx context.Context, querier dialect.Querier) ([]string, error) {
+	var (
+		names = []string{}
+		stmt  = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	)
+
+	err := query(ctx, querier, stmt, []interface{}{}, func(rows *sql.Rows) error {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		return nil
+	})
+
+	return names, err
+}
+
+func (mysql) columns(ctx context.Context, querier dialect.Querier, table *Table) error {
+	stmt := "SELECT column_name, column_type, is_nullable, column_default, extra " +
+		"FROM information_schema.columns " +
+		"WHERE table_schema = DATABASE() AND table_name = ? " +
+		"ORDER BY ordinal_position"
+
+	err := query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			column = &Column{}
+			null   string
+			value  sql.NullString
+			extra  string
+		)
+
+		if err := rows.Scan(&column.Name, &column.Type, &null, &value, &extra); err != nil {
+			return err
+		}
+
+		column.Nullable = null == "YES"
+		column.AutoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+		column.Default = nullable(value)
+
+		table.Columns = append(table.Columns, column)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	stmt = "SELECT column_name FROM information_schema.statistics " +
+		"WHERE table_schema = DATABASE() AND table_name = ? AND index_name = 'PRIMARY' " +
+		"ORDER BY seq_in_index"
+
+	return query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		table.PrimaryKey = append(table.PrimaryKey, name)
+		return nil
+	})
+}
+
+func (mysql) indexes(ctx context.Context, querier dialect.Querier, table *Table) error {
+	stmt := "SELECT index_name, non_unique, column_name FROM information_schema.statistics " +
+		"WHERE table_schema = DATABASE() AND table_name = ? AND index_name <> 'PRIMARY' " +
+		"ORDER BY index_name, seq_in_index"
+
+	return query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			name      string
+			nonunique bool
+			column    sql.NullString
+		)
+
+		if err := rows.Scan(&name, &nonunique, &column); err != nil {
+			return err
+		}
+
+		index := indexOf(table, name)
+		index.Unique = !nonunique
+		// the functional key parts do not have a column name
+		index.Columns = append(index.Columns, column.String)
+		return nil
+	})
+}
+
+func (mysql) foreignKeys(ctx context.Context, querier dialect.Querier, table *Table) error {
+	stmt := "SELECT kcu.constraint_name, kcu.column_name, kcu.referenced_table_name, kcu.referenced_column_name, rc.update_rule, rc.delete_rule " +
+		"FROM information_schema.key_column_usage AS kcu " +
+		"JOIN information_schema.referential_constraints AS rc " +
+		"ON rc.constraint_schema = kcu.constraint_schema AND rc.constraint_name = kcu.constraint_name " +
+		"WHERE kcu.table_schema = DATABASE() AND kcu.table_name = ? AND kcu.referenced_table_name IS NOT NULL " +
+		"ORDER BY kcu.constraint_name, kcu.ordinal_position"
+
+	return query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			name   string
+			column string
+			ref    string
+			target string
+			update string
+			delete string
+		)
+
+		if err := rows.Scan(&name, &column, &ref, &target, &update, &delete); err != nil {
+			return err
+		}
+
+		fk := foreignKeyOf(table, name)
+		fk.RefTable = ref
+		fk.OnUpdate = update
+		fk.OnDelete = delete
+		fk.Columns = append(fk.Columns, column)
+		fk.RefColumns = append(fk.RefColumns, target)
+		return nil
+	})
+}
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"context"
+	"strings"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// the oid of the table in the current schema
+const pgRelation = "(quote_ident(current_schema()) || '.' || quote_ident($1))::regclass"
+
+// the ON UPDATE and ON DELETE actions by their pg_constraint codes
+var pgActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// postgres inspects the current schema using the pg_catalog.
+type postgres struct{}
+
+func (postgres) tables(ctx context.Context, querier dialect.Querier) ([]string, error) {
+	var (
+		names = []string{}
+		stmt  = "SELECT c.relname FROM pg_catalog.pg_class AS c " +
+			"JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace " +
+			"WHERE c.relkind IN ('r', 'p') AND n.nspname = current_schema() " +
+			"ORDER BY c.relname"
+	)
+
+	err := query(ctx, querier, stmt, []interface{}{}, func(rows *sql.Rows) error {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		return nil
+	})
+
+	return names, err
+}
+
+func (postgres) columns(ctx context.Context, querier dialect.Querier, table *Table) error {
+	stmt := "SELECT a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_catalog.pg_get_expr(d.adbin, d.adrelid), a.attidentity <> '' " +
+		"FROM pg_catalog.pg_attribute AS a " +
+		"LEFT JOIN pg_catalog.pg_attrdef AS d ON d.adrelid = a.attrelid AND d.adnum = a.attnum " +
+		"WHERE a.attrelid = " + pgRelation + " AND a.attnum > 0 AND NOT a.attisdropped " +
+		"ORDER BY a.attnum"
+
+	err := query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			column = &Column{}
+			value  sql.NullString
+		)
+
+		if err := rows.Scan(&column.Name, &column.Type, &column.Nullable, &value, &column.AutoIncrement); err != nil {
+			return err
+		}
+
+		column.Default = nullable(value)
+		// the serial columns default to the next value of a sequence
+		column.AutoIncrement = column.AutoIncrement || strings.HasPrefix(value.String, "nextval(")
+
+		table.Columns = append(table.Columns, column)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	stmt = "SELECT a.attname FROM pg_catalog.pg_index AS i " +
+		"CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord) " +
+		"JOIN pg_catalog.pg_attribute AS a ON a.attrelid = i.indrelid AND a.attnum = k.attnum " +
+		"WHERE i.indrelid = " + pgRelation + " AND i.indisprimary " +
+		"ORDER BY k.ord"
+
+	return query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		table.PrimaryKey = append(table.PrimaryKey, name)
+		return nil
+	})
+}
+
+func (postgres) indexes(ctx context.Context, querier dialect.Querier, table *Table) error {
+	stmt := "SELECT c.relname, i.indisunique, a.attname FROM pg_catalog.pg_index AS i " +
+		"JOIN pg_catalog.pg_class AS c ON c.oid = i.indexrelid " +
+		"CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord) " +
+		"LEFT JOIN pg_catalog.pg_attribute AS a ON a.attrelid = i.indrelid AND a.attnum = k.attnum " +
+		"WHERE i.indrelid = " + pgRelation + " AND NOT i.indisprimary " +
+		"ORDER BY c.relname, k.ord"
+
+	return query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			name   string
+			unique bool
+			column sql.NullString
+		)
+
+		if err := rows.Scan(&name, &unique, &column); err != nil {
+			return err
+		}
+
+		index := indexOf(table, name)
+		index.Unique = unique
+		// the expressions do not have a column name
+		index.Columns = append(index.Columns, column.String)
+		return nil
+	})
+}
+
+func (postgres) foreignKeys(ctx context.Context, querier dialect.Querier, table *Table) error {
+	stmt := "SELECT con.conname, a.attname, r.relname, ra.attname, con.confupdtype, con.confdeltype " +
+		"FROM pg_catalog.pg_constraint AS con " +
+		"CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refnum, ord) " +
+		"JOIN pg_catalog.pg_attribute AS a ON a.attrelid = con.conrelid AND a.attnum = k.attnum " +
+		"JOIN pg_catalog.pg_class AS r ON r.oid = con.confrelid " +
+		"JOIN pg_catalog.pg_attribute AS ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refnum " +
+		"WHERE con.contype = 'f' AND con.conrelid = " + pgRelation + " " +
+		"ORDER BY con.conname, k.ord"
+
+	return query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			name   string
+			column string
+			ref    string
+			target string
+			update string
+			delete string
+		)
+
+		if err := rows.Scan(&name, &column, &ref, &target, &update, &delete); err != nil {
+			return err
+		}
+
+		fk := foreignKeyOf(table, name)
+		fk.RefTable = ref
+		fk.OnUpdate = pgActions[update]
+		fk.OnDelete = pgActions[delete]
+		fk.Columns = append(fk.Columns, column)
+		fk.RefColumns = append(fk.RefColumns, target)
+		return nil
+	})
+}
@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"context"
+	"sort"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// sqlite inspects the schema using sqlite_master and the pragma functions.
+type sqlite struct{}
+
+func (sqlite) tables(ctx context.Context, querier dialect.Querier) ([]string, error) {
+	var (
+		names = []string{}
+		stmt  = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	)
+
+	err := query(ctx, querier, stmt, []interface{}{}, func(rows *sql.Rows) error {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		return nil
+	})
+
+	return names, err
+}
+
+func (sqlite) columns(ctx context.Context, querier dialect.Querier, table *Table) error {
+	var (
+		primary = map[int]string{}
+		stmt    = `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`
+	)
+
+	err := query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			column  = &Column{}
+			notnull bool
+			value   sql.NullString
+			pk      int
+		)
+
+		if err := rows.Scan(&column.Name, &column.Type, &notnull, &value, &pk); err != nil {
+			return err
+		}
+
+		column.Nullable = !notnull
+		column.Default = nullable(value)
+
+		if pk > 0 {
+			primary[pk] = column.Name
+		}
+
+		table.Columns = append(table.Columns, column)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// pk is the position of the column in the primary key
+	positions := make([]int, 0, len(primary))
+	for position := range primary {
+		positions = append(positions, position)
+	}
+
+	sort.Ints(positions)
+
+	for _, position := range positions {
+		table.PrimaryKey = append(table.PrimaryKey, primary[position])
+	}
+
+	return nil
+}
+
+func (sqlite) indexes(ctx context.Context, querier dialect.Querier, table *Table) error {
+	var (
+		indexes = []*Index{}
+		stmt    = `SELECT name, "unique" FROM pragma_index_list(?) WHERE origin <> 'pk' ORDER BY name`
+	)
+
+	err := query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		index := &Index{}
+
+		if err := rows.Scan(&index.Name, &index.Unique); err != nil {
+			return err
+		}
+
+		indexes = append(indexes, index)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		stmt = "SELECT name FROM pragma_index_info(?) ORDER BY seqno"
+
+		err := query(ctx, querier, stmt, []interface{}{index.Name}, func(rows *sql.Rows) error {
+			var name sql.NullString
+
+			if err := rows.Scan(&name); err != nil {
+				return err
+			}
+
+			// the expressions do not have a name
+			index.Columns = append(index.Columns, name.String)
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		table.Indexes = append(table.Indexes, index)
+	}
+
+	return nil
+}
+
+func (sqlite) foreignKeys(ctx context.Context, querier dialect.Querier, table *Table) error {
+	var (
+		keys = map[int]*ForeignKey{}
+		stmt = `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`
+	)
+
+	err := query(ctx, querier, stmt, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var (
+			id      int
+			ref     string
+			column  string
+			target  sql.NullString
+			updated string
+			deleted string
+		)
+
+		if err := rows.Scan(&id, &ref, &column, &target, &updated, &deleted); err != nil {
+			return err
+		}
+
+		fk, ok := keys[id]
+		if !ok {
+			fk = &ForeignKey{
+				RefTable: ref,
+				OnUpdate: updated,
+				OnDelete: deleted,
+			}
+
+			keys[id] = fk
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+
+		fk.Columns = append(fk.Columns, column)
+		// the target is null if the foreign key references the primary key
+		fk.RefColumns = append(fk.RefColumns, target.String)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, fk := range table.ForeignKeys {
+		if fk.RefColumns[0] != "" {
+			continue
+		}
+
+		// the foreign key references the primary key
+		ref := &Table{Name: fk.RefTable}
+
+		if err := (sqlite{}).columns(ctx, querier, ref); err != nil {
+			return err
+		}
+
+		fk.RefColumns = ref.PrimaryKey
+	}
+
+	return nil
+}
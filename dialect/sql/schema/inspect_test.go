@@ -0,0 +1,124 @@
+package schema_test
+
+import (
+	"context"
+
+	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/orm/dialect/sql/schema"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inspector", func() {
+	var (
+		ctx       context.Context
+		driver    *sql.Driver
+		inspector *schema.Inspector
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		ctx = context.TODO()
+
+		driver, err = sql.Open("sqlite3", "file:inspect.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		statements := []string{
+			"CREATE TABLE groups (id TEXT PRIMARY KEY, name TEXT NOT NULL)",
+			"CREATE TABLE users (id INTEGER NOT NULL, tenant TEXT NOT NULL, name VARCHAR(255) DEFAULT 'guest', group_id TEXT REFERENCES groups ON DELETE CASCADE, PRIMARY KEY (tenant, id))",
+			"CREATE UNIQUE INDEX users_name ON users (tenant, name)",
+			"CREATE INDEX users_group ON users (group_id)",
+		}
+
+		for _, query := range statements {
+			Expect(driver.Exec(ctx, query, []interface{}{}, nil)).To(Succeed())
+		}
+
+		inspector, err = schema.NewInspector(driver)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(driver.Exec(ctx, "DROP TABLE users", []interface{}{}, nil)).To(Succeed())
+		Expect(driver.Exec(ctx, "DROP TABLE groups", []interface{}{}, nil)).To(Succeed())
+		Expect(driver.Close()).To(Succeed())
+	})
+
+	Describe("Inspect", func() {
+		It("reads the schema", func() {
+			value, err := inspector.Inspect(ctx)
+			Expect(err).To(BeNil())
+			Expect(value.Tables).To(HaveLen(2))
+			Expect(value.Tables[0].Name).To(Equal("groups"))
+			Expect(value.Tables[1].Name).To(Equal("users"))
+			Expect(value.Table("unknown")).To(BeNil())
+
+			table := value.Table("groups")
+			Expect(table.PrimaryKey).To(Equal([]string{"id"}))
+			Expect(table.Indexes).To(BeEmpty())
+			Expect(table.ForeignKeys).To(BeEmpty())
+		})
+	})
+
+	Describe("InspectTable", func() {
+		It("reads the columns", func() {
+			table, err := inspector.InspectTable(ctx, "users")
+			Expect(err).To(BeNil())
+			Expect(table.Columns).To(HaveLen(4))
+			Expect(table.PrimaryKey).To(Equal([]string{"tenant", "id"}))
+
+			column := table.Column("name")
+			Expect(column.Type).To(Equal("VARCHAR(255)"))
+			Expect(column.Nullable).To(BeTrue())
+			Expect(column.Default).To(HaveValue(Equal("'guest'")))
+
+			column = table.Column("id")
+			Expect(column.Type).To(Equal("INTEGER"))
+			Expect(column.Nullable).To(BeFalse())
+			Expect(column.Default).To(BeNil())
+		})
+
+		It("reads the indexes", func() {
+			table, err := inspector.InspectTable(ctx, "users")
+			Expect(err).To(BeNil())
+			Expect(table.Indexes).To(Equal([]*schema.Index{
+				{Name: "users_group", Columns: []string{"group_id"}},
+				{Name: "users_name", Unique: true, Columns: []string{"tenant", "name"}},
+			}))
+			Expect(table.Index("users_name").Unique).To(BeTrue())
+		})
+
+		It("reads the foreign keys", func() {
+			table, err := inspector.InspectTable(ctx, "users")
+			Expect(err).To(BeNil())
+			Expect(table.ForeignKeys).To(Equal([]*schema.ForeignKey{
+				{
+					Columns:    []string{"group_id"},
+					RefTable:   "groups",
+					RefColumns: []string{"id"},
+					OnUpdate:   "NO ACTION",
+					OnDelete:   "CASCADE",
+				},
+			}))
+		})
+
+		Context("when the table does not exist", func() {
+			It("returns an error", func() {
+				table, err := inspector.InspectTable(ctx, "unknown")
+				Expect(err).To(MatchError(`schema: table "unknown" does not exist`))
+				Expect(table).To(BeNil())
+			})
+		})
+	})
+
+	Context("when the dialect is not supported", func() {
+		It("returns an error", func() {
+			inspector, err := schema.NewInspector(sql.OpenDB("oracle", driver.DB()))
+			Expect(err).To(MatchError(`schema: unsupported dialect "oracle"`))
+			Expect(inspector).To(BeNil())
+		})
+	})
+})
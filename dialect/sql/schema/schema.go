@@ -0,0 +1,99 @@
+// Package schema provides primitives to inspect the schema of a live
+// database and to migrate it to the schema of the entities.
+package schema
+
+// Schema represents the schema of a database.
+type Schema struct {
+	// Tables are the tables of the schema ordered by name.
+	Tables []*Table
+}
+
+// Table returns the table with the given name or nil if it does not exist.
+func (s *Schema) Table(name string) *Table {
+	for _, table := range s.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+
+	return nil
+}
+
+// Table represents a database table.
+type Table struct {
+	// Name is the name of the table.
+	Name string
+	// Columns are the columns of the table in their ordinal position.
+	Columns []*Column
+	// PrimaryKey are the columns of the primary key.
+	PrimaryKey []string
+	// Indexes are the indexes of the table. The primary key index is not
+	// included.
+	Indexes []*Index
+	// ForeignKeys are the foreign keys of the table.
+	ForeignKeys []*ForeignKey
+}
+
+// Column returns the column with the given name or nil if it does not exist.
+func (t *Table) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+
+	return nil
+}
+
+// Index returns the index with the given name or nil if it does not exist.
+func (t *Table) Index(name string) *Index {
+	for _, index := range t.Indexes {
+		if index.Name == name {
+			return index
+		}
+	}
+
+	return nil
+}
+
+// Column represents a table column.
+type Column struct {
+	// Name is the name of the column.
+	Name string
+	// Type is the column type as reported by the database.
+	Type string
+	// Nullable reports whether the column accepts NULL values.
+	Nullable bool
+	// Default is the default value expression. It's nil if the column does
+	// not have a default value.
+	Default *string
+	// AutoIncrement reports whether the column is auto incremented. It's not
+	// reported by SQLite.
+	AutoIncrement bool
+}
+
+// Index represents a table index.
+type Index struct {
+	// Name is the name of the index.
+	Name string
+	// Unique reports whether the index is unique.
+	Unique bool
+	// Columns are the indexed columns.
+	Columns []string
+}
+
+// ForeignKey represents a foreign key constraint.
+type ForeignKey struct {
+	// Name is the name of the constraint. It's empty in SQLite.
+	Name string
+	// Columns are the columns of the foreign key.
+	Columns []string
+	// RefTable is the referenced table.
+	RefTable string
+	// RefColumns are the referenced columns.
+	RefColumns []string
+	// OnUpdate is the ON UPDATE action (e.g. CASCADE).
+	OnUpdate string
+	// OnDelete is the ON DELETE action (e.g. SET NULL).
+	OnDelete string
+}
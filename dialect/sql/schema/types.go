@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/phogolabs/orm/dialect"
+)
+
+var (
+	// the display width of the MySQL integer types
+	mysqlWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\((\d+)\)`)
+	// the size of the types like varchar(255) or numeric(20,0)
+	typeSize = regexp.MustCompile(`^([a-z ]+?)\s*\(([\d, ]+)\)(.*)$`)
+)
+
+// the aliases of the PostgreSQL types
+var pgAliases = map[string]string{
+	"character varying":           "varchar",
+	"character":                   "char",
+	"int":                         "integer",
+	"int2":                        "smallint",
+	"int4":                        "integer",
+	"int8":                        "bigint",
+	"bool":                        "boolean",
+	"float4":                      "real",
+	"float8":                      "double precision",
+	"decimal":                     "numeric",
+	"timestamptz":                 "timestamp with time zone",
+	"timestamp":                   "timestamp without time zone",
+	"timetz":                      "time with time zone",
+	"time":                        "time without time zone",
+	"serial":                      "integer",
+	"bigserial":                   "bigint",
+	"smallserial":                 "smallint",
+	"timestamp without time zone": "timestamp without time zone",
+}
+
+// the sizes of the types used to detect the narrowing conversions
+var (
+	integerSizes = map[string]float64{
+		"tinyint":   1,
+		"smallint":  2,
+		"mediumint": 3,
+		"int":       4,
+		"integer":   4,
+		"bigint":    8,
+	}
+
+	floatSizes = map[string]float64{
+		"float":            4,
+		"real":             4,
+		"double":           8,
+		"double precision": 8,
+	}
+
+	textSizes = map[string]float64{
+		"tinytext":   255,
+		"text":       math.MaxUint32,
+		"mediumtext": 16777215,
+		"longtext":   math.MaxUint32,
+	}
+)
+
+// normalize returns the canonical form of the column type in the given
+// dialect. It's used to compare the types reported by the database with the
+// desired ones.
+func normalize(name, typ string) string {
+	typ = strings.ToLower(strings.Join(strings.Fields(typ), " "))
+
+	switch name {
+	case dialect.MySQL:
+		switch typ {
+		case "bool", "boolean":
+			return "tinyint(1)"
+		case "integer":
+			return "int"
+		}
+
+		if match := mysqlWidth.FindStringSubmatch(typ); match != nil {
+			// tinyint(1) is the boolean type
+			if match[1] == "tinyint" && match[2] == "1" {
+				return typ
+			}
+
+			typ = match[1] + strings.TrimPrefix(typ, match[0])
+		}
+
+		if strings.HasPrefix(typ, "integer ") {
+			typ = "int" + strings.TrimPrefix(typ, "integer")
+		}
+	case dialect.Postgres:
+		base, size, rest := typ, "", ""
+
+		if match := typeSize.FindStringSubmatch(typ); match != nil {
+			base, size, rest = match[1], strings.ReplaceAll(match[2], " ", ""), match[3]
+		}
+
+		if alias, ok := pgAliases[base]; ok {
+			base = alias
+		}
+
+		if base == "numeric" && size != "" && !strings.Contains(size, ",") {
+			size += ",0"
+		}
+
+		typ = base
+		if size != "" {
+			typ += "(" + size + ")"
+		}
+		typ += rest
+	}
+
+	return typ
+}
+
+// narrowing reports whether converting a column from one type to another
+// may lose data. The types must be normalized.
+func narrowing(from, to string) bool {
+	if from == to {
+		return false
+	}
+
+	fromKind, fromSize, fromUnsigned := sizeOf(from)
+	toKind, toSize, toUnsigned := sizeOf(to)
+
+	switch {
+	case fromKind == "" || toKind == "":
+		// unknown types are always narrowing
+		return true
+	case fromKind != toKind:
+		return true
+	case fromUnsigned && !toUnsigned:
+		return toSize <= fromSize
+	case !fromUnsigned && toUnsigned:
+		return true
+	default:
+		return toSize < fromSize
+	}
+}
+
+// sizeOf returns the kind (integer, float or text) and the size of the type.
+func sizeOf(typ string) (string, float64, bool) {
+	unsigned := strings.HasSuffix(typ, " unsigned")
+	typ = strings.TrimSuffix(typ, " unsigned")
+
+	if size, ok := integerSizes[typ]; ok {
+		return "integer", size, unsigned
+	}
+
+	if size, ok := floatSizes[typ]; ok {
+		return "float", size, unsigned
+	}
+
+	if size, ok := textSizes[typ]; ok {
+		return "text", size, false
+	}
+
+	if typ == "varchar" {
+		return "text", math.MaxUint32, false
+	}
+
+	if match := typeSize.FindStringSubmatch(typ); match != nil && match[3] == "" {
+		switch match[1] {
+		case "varchar", "char":
+			if size, err := strconv.ParseFloat(match[2], 64); err == nil {
+				return "text", size, false
+			}
+		}
+	}
+
+	return "", 0, false
+}
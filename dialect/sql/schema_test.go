@@ -0,0 +1,176 @@
+package sql_test
+
+import (
+	"context"
+	stdsql "database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/phogolabs/orm/dialect"
+	"github.com/phogolabs/orm/dialect/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type SchemaGroup struct {
+	ID   string `db:"id,primary_key,size=36"`
+	Name string `db:"name,unique"`
+}
+
+func (SchemaGroup) TableName() string {
+	return "groups"
+}
+
+type SchemaUser struct {
+	ID        int64               `db:"id,primary_key,auto"`
+	Email     string              `db:"email,size=128,unique_index"`
+	FirstName *string             `db:"first_name,index=users_name"`
+	LastName  stdsql.NullString   `db:"last_name,index=users_name"`
+	Age       uint8               `db:"age"`
+	Score     float64             `db:"score"`
+	Active    bool                `db:"active"`
+	Settings  json.RawMessage     `db:"settings,type=text"`
+	Group     *SchemaGroup        `db:"group,foreign_key=group_id,reference_key=id,on_delete=set_null"`
+	CreatedAt time.Time           `db:"created_at,auto"`
+	Ignored   string              `db:"-"`
+	Avatar    stdsql.Null[[]byte] `db:"avatar"`
+}
+
+var _ = Describe("CreateTableFrom", func() {
+	It("creates the table for MySQL", func() {
+		query, args := sql.Dialect(dialect.MySQL).CreateTableFrom(&SchemaUser{}).Query()
+		Expect(args).To(BeEmpty())
+		Expect(query).To(Equal("CREATE TABLE `schema_users`(" +
+			"`id` bigint NOT NULL AUTO_INCREMENT, " +
+			"`email` varchar(128) NOT NULL, " +
+			"`first_name` varchar(255), " +
+			"`last_name` varchar(255), " +
+			"`age` tinyint unsigned NOT NULL, " +
+			"`score` double NOT NULL, " +
+			"`active` boolean NOT NULL, " +
+			"`settings` text NOT NULL, " +
+			"`group_id` varchar(36), " +
+			"`created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+			"`avatar` blob, " +
+			"PRIMARY KEY(`id`), " +
+			"FOREIGN KEY(`group_id`) REFERENCES `groups`(`id`) ON DELETE SET NULL)"))
+	})
+
+	It("creates the table for PostgreSQL", func() {
+		query, _ := sql.Dialect(dialect.Postgres).CreateTableFrom(SchemaGroup{}).IfNotExists().Query()
+		Expect(query).To(Equal(`CREATE TABLE IF NOT EXISTS "groups"("id" varchar(36) NOT NULL, "name" varchar NOT NULL UNIQUE, PRIMARY KEY("id"))`))
+
+		query, _ = sql.Dialect(dialect.Postgres).CreateTableFrom(&SchemaUser{}).Query()
+		Expect(query).To(HavePrefix(`CREATE TABLE "schema_users"("id" bigint NOT NULL GENERATED BY DEFAULT AS IDENTITY, `))
+		Expect(query).To(ContainSubstring(`"created_at" timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP`))
+		Expect(query).To(ContainSubstring(`"avatar" bytea`))
+	})
+
+	It("creates the indexes", func() {
+		indexes := sql.Dialect(dialect.Postgres).CreateIndexesFrom(&SchemaUser{})
+		Expect(indexes).To(HaveLen(2))
+
+		query, _ := indexes[0].Query()
+		Expect(query).To(Equal(`CREATE UNIQUE INDEX "schema_users_email" ON "schema_users"("email")`))
+
+		query, _ = indexes[1].Query()
+		Expect(query).To(Equal(`CREATE INDEX "users_name" ON "schema_users"("first_name", "last_name")`))
+	})
+
+	Context("when the type is not supported", func() {
+		It("returns an error", func() {
+			type Entity struct {
+				Tags map[string]string `db:"tags"`
+			}
+
+			table := sql.CreateTableFrom(&Entity{})
+			table.Query()
+			Expect(table.Err()).To(MatchError(`sql: unsupported type map[string]string of column "tags"`))
+		})
+	})
+
+	Context("when the column is a nested struct", func() {
+		It("returns an error", func() {
+			type Address struct {
+				City string `db:"city"`
+			}
+
+			type Entity struct {
+				ID      int     `db:"id,primary_key"`
+				Address Address `db:"address"`
+			}
+
+			table := sql.CreateTableFrom(&Entity{})
+			table.Query()
+			Expect(table.Err()).To(MatchError(`sql: column "address" is a nested struct sql_test.Address. add the foreign_key option or ignore it with db:"-"`))
+		})
+	})
+
+	Context("when the entity is not a struct", func() {
+		It("returns an error", func() {
+			table := sql.CreateTableFrom(1)
+			table.Query()
+			Expect(table.Err()).To(MatchError("sql: invalid type int. expected struct as an argument"))
+		})
+	})
+
+	Context("when the dialect is SQLite", func() {
+		var (
+			ctx    context.Context
+			driver *sql.Driver
+		)
+
+		exec := func(query sql.Querier) {
+			stmt, args := query.Query()
+			Expect(driver.Exec(ctx, stmt, args, nil)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+
+			ctx = context.TODO()
+
+			driver, err = sql.Open("sqlite3", "file:schema.db?cache=shared&mode=memory")
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			Expect(driver.Close()).To(Succeed())
+		})
+
+		It("bootstraps the database", func() {
+			builder := sql.Dialect(dialect.SQLite)
+
+			exec(builder.CreateTableFrom(&SchemaGroup{}))
+			exec(builder.CreateTableFrom(&SchemaUser{}))
+
+			for _, index := range builder.CreateIndexesFrom(&SchemaUser{}) {
+				exec(index)
+			}
+
+			query, _ := builder.CreateTableFrom(&SchemaUser{}).Query()
+			Expect(query).To(HavePrefix("CREATE TABLE `schema_users`(`id` integer NOT NULL PRIMARY KEY AUTOINCREMENT, "))
+			Expect(query).NotTo(ContainSubstring("PRIMARY KEY(`id`)"))
+
+			exec(builder.Insert("groups").Columns("id", "name").Values("admin", "Admin"))
+			exec(builder.Insert("schema_users").Columns("email", "age", "score", "active", "settings", "group_id").
+				Values("john@example.com", 30, 1.5, true, "{}", "admin"))
+
+			rows := &sql.Rows{}
+			Expect(driver.Query(ctx, "SELECT id, created_at FROM schema_users", []interface{}{}, rows)).To(Succeed())
+			Expect(rows.Next()).To(BeTrue())
+
+			var (
+				id        int64
+				createdAt time.Time
+			)
+
+			Expect(rows.Scan(&id, &createdAt)).To(Succeed())
+			Expect(rows.Close()).To(Succeed())
+			Expect(id).To(BeEquivalentTo(1))
+			Expect(createdAt).NotTo(BeZero())
+		})
+	})
+})
@@ -0,0 +1,191 @@
+package sql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/phogolabs/orm/dialect"
+)
+
+// stmtCache is a least recently used cache of prepared statements keyed by
+// their SQL text.
+type stmtCache struct {
+	mu      sync.Mutex
+	db      *sql.DB
+	dialect string
+	size    int
+	items   map[string]*list.Element
+	order   *list.List
+}
+
+// stmtEntry is a cached prepared statement.
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, dialect string, size int) *stmtCache {
+	return &stmtCache{
+		db:      db,
+		dialect: dialect,
+		size:    size,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// acquire returns the prepared statement of the given query. It prepares the
+// statement if it's not cached. The entry must be released after use.
+func (c *stmtCache) acquire(ctx context.Context, query string) (*stmtEntry, error) {
+	if entry := c.lookup(query); entry != nil {
+		return entry, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+
+	// the statement might have been prepared by another caller meanwhile
+	if element, ok := c.items[query]; ok {
+		entry := element.Value.(*stmtEntry)
+		entry.refs++
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+
+		stmt.Close()
+		return entry, nil
+	}
+
+	entry := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.order.PushFront(entry)
+
+	var evicted []*sql.Stmt
+
+	for c.order.Len() > c.size {
+		if stmt := c.evict(c.order.Back()); stmt != nil {
+			evicted = append(evicted, stmt)
+		}
+	}
+
+	c.mu.Unlock()
+
+	for _, stmt := range evicted {
+		stmt.Close()
+	}
+
+	return entry, nil
+}
+
+// lookup returns the cached entry of the given query or nil.
+func (c *stmtCache) lookup(query string) *stmtEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[query]
+	if !ok {
+		return nil
+	}
+
+	entry := element.Value.(*stmtEntry)
+	entry.refs++
+	c.order.MoveToFront(element)
+	return entry
+}
+
+// release releases the entry acquired from the cache. The statement is closed
+// if it was evicted while in use.
+func (c *stmtCache) release(entry *stmtEntry) {
+	c.mu.Lock()
+	entry.refs--
+	closed := entry.evicted && entry.refs == 0
+	c.mu.Unlock()
+
+	if closed {
+		entry.stmt.Close()
+	}
+}
+
+// evict removes the element from the cache. It returns the statement that
+// has to be closed or nil if the statement is still in use.
+func (c *stmtCache) evict(element *list.Element) *sql.Stmt {
+	entry := c.order.Remove(element).(*stmtEntry)
+	entry.evicted = true
+	delete(c.items, entry.query)
+
+	if entry.refs > 0 {
+		return nil
+	}
+
+	return entry.stmt
+}
+
+// close evicts and closes all cached statements.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+
+	var evicted []*sql.Stmt
+
+	for c.order.Len() > 0 {
+		if stmt := c.evict(c.order.Back()); stmt != nil {
+			evicted = append(evicted, stmt)
+		}
+	}
+
+	c.mu.Unlock()
+
+	var err error
+
+	for _, stmt := range evicted {
+		if cerr := stmt.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// cacheable reports whether the query can be cached as a prepared statement.
+// Only single data manipulation statements are cached. The scripts with many
+// statements (e.g. routines) and the transaction control statements (e.g.
+// savepoints with unique names) are executed directly. The query is tokenized
+// in the given dialect.
+func cacheable(query, name string) bool {
+	var (
+		word        string
+		end         bool
+		tokens, err = dialect.Tokenize(query, name)
+	)
+
+	if err != nil {
+		return false
+	}
+
+	for _, token := range tokens {
+		switch {
+		case token.Kind == dialect.TokenSpace || token.Kind == dialect.TokenComment:
+		case end:
+			// there is another statement after the terminator
+			return false
+		case token.Kind == dialect.TokenPunct && token.Raw == ";":
+			end = true
+		case word == "" && token.Kind == dialect.TokenWord:
+			word = strings.ToUpper(token.Raw)
+
+			switch word {
+			case "SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE", "WITH", "VALUES":
+			default:
+				return false
+			}
+		}
+	}
+
+	return word != ""
+}
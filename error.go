@@ -1,8 +1,10 @@
 package orm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // NotFoundError returns when trying to fetch a specific entity and it was not found in the database.
@@ -107,3 +109,201 @@ func IsConstraintViolation(err error) bool {
 	var e *ConstraintError
 	return errors.As(err, &e)
 }
+
+// QueryError returns when the gateway fails to execute a query. It describes
+// the failed query and wraps the actual error.
+type QueryError struct {
+	// Operation is the name of the gateway's operation (e.g. All, First, Only
+	// or Exec).
+	Operation string
+	// Routine is the name of the routine. It's empty if the query is not a
+	// routine.
+	Routine string
+	// Query is the compiled SQL query. It's empty if the query cannot be
+	// compiled or the gateway is configured to strip it.
+	Query string
+	// NumArgs is the number of the query arguments.
+	NumArgs int
+	// the actual error
+	err error
+}
+
+// Error implements the error interface.
+func (e *QueryError) Error() string {
+	buffer := &strings.Builder{}
+	buffer.WriteString("orm: ")
+	buffer.WriteString(strings.ToLower(e.Operation))
+
+	switch {
+	case e.Routine != "":
+		fmt.Fprintf(buffer, " routine %q", e.Routine)
+	case e.Query != "":
+		fmt.Fprintf(buffer, " %q", e.Query)
+	}
+
+	if e.err != nil {
+		buffer.WriteString(": ")
+		// the errors of the package have the same prefix
+		buffer.WriteString(strings.TrimPrefix(e.err.Error(), "orm: "))
+	}
+
+	return buffer.String()
+}
+
+// Unwrap implements the errors.Wrapper interface.
+func (e *QueryError) Unwrap() error {
+	return e.err
+}
+
+// ErrorKind represents the kind of a database error.
+type ErrorKind int
+
+const (
+	// UnknownError is an error that is not classified.
+	UnknownError ErrorKind = iota
+	// UniqueViolation is a violation of a unique or primary key constraint.
+	UniqueViolation
+	// ForeignKeyViolation is a violation of a foreign key constraint.
+	ForeignKeyViolation
+	// NotNullViolation is a violation of a not null constraint.
+	NotNullViolation
+	// CheckViolation is a violation of a check constraint.
+	CheckViolation
+	// SerializationFailure is a failure to serialize concurrent transactions.
+	SerializationFailure
+	// Deadlock is a deadlock detected by the database.
+	Deadlock
+	// LockTimeout is a failure to acquire a lock in time.
+	LockTimeout
+	// QueryTimeout is a query canceled due to a statement timeout.
+	QueryTimeout
+	// ConnectionFailure is a lost or refused connection.
+	ConnectionFailure
+)
+
+// String returns the kind as string.
+func (k ErrorKind) String() string {
+	switch k {
+	case UniqueViolation:
+		return "unique violation"
+	case ForeignKeyViolation:
+		return "foreign key violation"
+	case NotNullViolation:
+		return "not null violation"
+	case CheckViolation:
+		return "check violation"
+	case SerializationFailure:
+		return "serialization failure"
+	case Deadlock:
+		return "deadlock"
+	case LockTimeout:
+		return "lock timeout"
+	case QueryTimeout:
+		return "query timeout"
+	case ConnectionFailure:
+		return "connection failure"
+	default:
+		return "unknown error"
+	}
+}
+
+// IsConstraint reports whether the kind is a constraint violation.
+func (k ErrorKind) IsConstraint() bool {
+	switch k {
+	case UniqueViolation, ForeignKeyViolation, NotNullViolation, CheckViolation:
+		return true
+	default:
+		return false
+	}
+}
+
+// DatabaseError returns when the database fails to execute a statement. It's
+// created by the ErrorTranslator of the dialect from the driver error.
+type DatabaseError struct {
+	// Kind is the kind of the error.
+	Kind ErrorKind
+	// Table is the name of the table (if known).
+	Table string
+	// Column is the name of the column (if known).
+	Column string
+	// Constraint is the name of the violated constraint (if known).
+	Constraint string
+	// Err is the underlying driver error.
+	Err error
+}
+
+// Error implements the error interface. It returns the message of the
+// underlying driver error.
+func (e *DatabaseError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+
+	return "orm: " + e.Kind.String()
+}
+
+// Unwrap implements the errors.Wrapper interface.
+func (e *DatabaseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKindOf returns the kind of the database error. It returns UnknownError
+// if the error is not a database error.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return UnknownError
+	}
+	var e *DatabaseError
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return UnknownError
+}
+
+// IsUniqueViolation returns a boolean indicating whether the error is a unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	return ErrorKindOf(err) == UniqueViolation
+}
+
+// IsForeignKeyViolation returns a boolean indicating whether the error is a foreign key constraint violation.
+func IsForeignKeyViolation(err error) bool {
+	return ErrorKindOf(err) == ForeignKeyViolation
+}
+
+// IsNotNullViolation returns a boolean indicating whether the error is a not null constraint violation.
+func IsNotNullViolation(err error) bool {
+	return ErrorKindOf(err) == NotNullViolation
+}
+
+// IsCheckViolation returns a boolean indicating whether the error is a check constraint violation.
+func IsCheckViolation(err error) bool {
+	return ErrorKindOf(err) == CheckViolation
+}
+
+// IsRetryable returns a boolean indicating whether the operation that failed
+// with the error can be retried. For example, serialization failures,
+// deadlocks and lock timeouts.
+func IsRetryable(err error) bool {
+	switch ErrorKindOf(err) {
+	case SerializationFailure, Deadlock, LockTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTimeout returns a boolean indicating whether the error is a lock or query
+// timeout, or the deadline of the context exceeded.
+func IsTimeout(err error) bool {
+	switch ErrorKindOf(err) {
+	case LockTimeout, QueryTimeout:
+		return true
+	default:
+		return errors.Is(err, context.DeadlineExceeded)
+	}
+}
+
+// IsConnectionFailure returns a boolean indicating whether the error is a lost or refused connection.
+func IsConnectionFailure(err error) bool {
+	return ErrorKindOf(err) == ConnectionFailure
+}
@@ -0,0 +1,433 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/phogolabs/orm/dialect"
+)
+
+// ErrorTranslator translates the errors returned by the database driver of a
+// dialect into database errors.
+type ErrorTranslator interface {
+	// Translate returns the database error for the given driver error or nil
+	// if the error is not recognized.
+	Translate(err error) *DatabaseError
+}
+
+// ErrorTranslatorFunc is a function that implements the ErrorTranslator
+// interface.
+type ErrorTranslatorFunc func(err error) *DatabaseError
+
+// Translate calls fn(err).
+func (fn ErrorTranslatorFunc) Translate(err error) *DatabaseError {
+	return fn(err)
+}
+
+var translators = struct {
+	sync.RWMutex
+	items map[string]ErrorTranslator
+}{
+	items: map[string]ErrorTranslator{
+		dialect.SQLite:   ErrorTranslatorFunc(translateSQLite),
+		dialect.MySQL:    ErrorTranslatorFunc(translateMySQL),
+		dialect.Postgres: ErrorTranslatorFunc(translatePostgres),
+	},
+}
+
+// RegisterErrorTranslator registers the error translator of the given
+// dialect. The translator is used for the drivers whose name starts with the
+// dialect name. It replaces the translator registered for the same dialect.
+func RegisterErrorTranslator(name string, translator ErrorTranslator) {
+	translators.Lock()
+	defer translators.Unlock()
+
+	translators.items[name] = translator
+}
+
+// translatorOf returns the translator of the given dialect. The name of the
+// dialect may be the name of a wrapped driver (e.g. sqlite3-instrumented). A
+// registered dialect that does not implement ErrorTranslator uses the
+// translator of its family.
+func translatorOf(name string) ErrorTranslator {
+	translators.RLock()
+	defer translators.RUnlock()
+
+	if translator, ok := translators.items[name]; ok {
+		return translator
+	}
+
+	if spec, ok := dialect.Lookup(name); ok {
+		if translator, ok := spec.(ErrorTranslator); ok {
+			return translator
+		}
+
+		if translator, ok := translators.items[spec.Family()]; ok {
+			return translator
+		}
+	}
+
+	var (
+		prefix     string
+		translator ErrorTranslator
+	)
+
+	for key, item := range translators.items {
+		if strings.HasPrefix(name, key) && len(key) > len(prefix) {
+			prefix = key
+			translator = item
+		}
+	}
+
+	return translator
+}
+
+// TranslateError translates the driver error with the error translator of
+// the given dialect. The constraint violations are wrapped by ConstraintError
+// and the other recognized errors are returned as DatabaseError. The error is
+// returned as it is if it cannot be recognized.
+func TranslateError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	errx := translate(name, err)
+
+	switch {
+	case errx == nil:
+		return err
+	case errx.Kind.IsConstraint():
+		name := errx.Constraint
+		// SQLite reports only the table and the column
+		if name == "" && errx.Table != "" {
+			name = errx.Table + "." + errx.Column
+		}
+
+		return &ConstraintError{
+			name: name,
+			wrap: errx,
+		}
+	default:
+		return errx
+	}
+}
+
+// translate returns the database error of the given driver error. It returns
+// nil if the error cannot be classified.
+func translate(name string, err error) *DatabaseError {
+	var errx *DatabaseError
+	// the error is already translated
+	if errors.As(err, &errx) {
+		return nil
+	}
+
+	if translator := translatorOf(name); translator != nil {
+		if errx = translator.Translate(err); errx != nil {
+			return errx
+		}
+	}
+
+	return translateMessage(err)
+}
+
+// translateSQLite translates the errors of SQLite by their extended result
+// codes. See https://www.sqlite.org/rescode.html.
+func translateSQLite(err error) *DatabaseError {
+	type Coder interface {
+		Code() int
+	}
+
+	var (
+		coder Coder
+		errx  = &DatabaseError{Err: err}
+	)
+
+	code, ok := codeOf(err, "ExtendedCode", "Code")
+	// modernc.org/sqlite exposes the extended code by a method
+	if errors.As(err, &coder) {
+		code, ok = int64(coder.Code()), true
+	}
+
+	if !ok {
+		return nil
+	}
+
+	switch code {
+	// SQLITE_CONSTRAINT_UNIQUE, SQLITE_CONSTRAINT_PRIMARYKEY
+	case 2067, 1555:
+		errx.Kind = UniqueViolation
+	// SQLITE_CONSTRAINT_FOREIGNKEY
+	case 787:
+		errx.Kind = ForeignKeyViolation
+	// SQLITE_CONSTRAINT_NOTNULL
+	case 1299:
+		errx.Kind = NotNullViolation
+	// SQLITE_CONSTRAINT_CHECK
+	case 275:
+		errx.Kind = CheckViolation
+	// SQLITE_BUSY and SQLITE_LOCKED with their extended codes
+	case 5, 261, 517, 773, 6, 262, 518:
+		errx.Kind = LockTimeout
+	// SQLITE_INTERRUPT
+	case 9:
+		errx.Kind = QueryTimeout
+	default:
+		return nil
+	}
+
+	// UNIQUE constraint failed: users.email
+	// CHECK constraint failed: users_age_check
+	if _, detail, ok := strings.Cut(err.Error(), " constraint failed: "); ok {
+		detail, _, _ = strings.Cut(detail, ",")
+
+		if table, column, ok := strings.Cut(detail, "."); ok {
+			errx.Table = table
+			errx.Column = column
+		} else {
+			errx.Constraint = detail
+		}
+	}
+
+	return errx
+}
+
+var (
+	mysqlKey        = regexp.MustCompile("for key '(?:([^'.]+)\\.)?([^']+)'")
+	mysqlForeignKey = regexp.MustCompile("\\(`[^`]+`\\.`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`\\)")
+	mysqlColumn     = regexp.MustCompile("(?:Column|Field) '([^']+)'")
+	mysqlCheck      = regexp.MustCompile("Check constraint '([^']+)'")
+)
+
+// translateMySQL translates the errors of MySQL by their error numbers. See
+// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html.
+func translateMySQL(err error) *DatabaseError {
+	code, ok := codeOf(err, "Number")
+	if !ok {
+		return translateConnection(err)
+	}
+
+	var (
+		errm = err.Error()
+		errx = &DatabaseError{Err: err}
+	)
+
+	switch code {
+	// ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+	case 1062, 1586:
+		errx.Kind = UniqueViolation
+
+		if match := mysqlKey.FindStringSubmatch(errm); match != nil {
+			errx.Table = match[1]
+			errx.Constraint = match[2]
+		}
+	// ER_NO_REFERENCED_ROW, ER_ROW_IS_REFERENCED, ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+	case 1216, 1217, 1451, 1452:
+		errx.Kind = ForeignKeyViolation
+
+		if match := mysqlForeignKey.FindStringSubmatch(errm); match != nil {
+			errx.Table = match[1]
+			errx.Constraint = match[2]
+			errx.Column = match[3]
+		}
+	// ER_BAD_NULL_ERROR, ER_NO_DEFAULT_FOR_FIELD
+	case 1048, 1364:
+		errx.Kind = NotNullViolation
+
+		if match := mysqlColumn.FindStringSubmatch(errm); match != nil {
+			errx.Column = match[1]
+		}
+	// ER_CHECK_CONSTRAINT_VIOLATED
+	case 3819:
+		errx.Kind = CheckViolation
+
+		if match := mysqlCheck.FindStringSubmatch(errm); match != nil {
+			errx.Constraint = match[1]
+		}
+	// ER_LOCK_DEADLOCK
+	case 1213:
+		errx.Kind = Deadlock
+	// ER_LOCK_WAIT_TIMEOUT, ER_LOCK_NOWAIT
+	case 1205, 3572:
+		errx.Kind = LockTimeout
+	// ER_QUERY_TIMEOUT, ER_QUERY_INTERRUPTED
+	case 3024, 1317:
+		errx.Kind = QueryTimeout
+	// ER_SERVER_SHUTDOWN, ER_CONNECTION_KILLED
+	case 1053, 1927:
+		errx.Kind = ConnectionFailure
+	default:
+		return nil
+	}
+
+	return errx
+}
+
+// translatePostgres translates the errors of PostgreSQL by their SQLSTATE
+// codes. It supports both lib/pq and pgx drivers. See
+// https://www.postgresql.org/docs/current/errcodes-appendix.html.
+func translatePostgres(err error) *DatabaseError {
+	type SQLStater interface {
+		SQLState() string
+	}
+
+	var (
+		state  string
+		stater SQLStater
+		errx   = &DatabaseError{Err: err}
+	)
+
+	if errors.As(err, &stater) {
+		state = stater.SQLState()
+	} else {
+		state, _ = fieldOf(err, "Code")
+	}
+
+	switch {
+	case state == "":
+		return translateConnection(err)
+	case state == "23505":
+		errx.Kind = UniqueViolation
+	case state == "23503":
+		errx.Kind = ForeignKeyViolation
+	case state == "23502":
+		errx.Kind = NotNullViolation
+	case state == "23514":
+		errx.Kind = CheckViolation
+	case state == "40001":
+		errx.Kind = SerializationFailure
+	case state == "40P01":
+		errx.Kind = Deadlock
+	case state == "55P03":
+		errx.Kind = LockTimeout
+	case state == "57014":
+		errx.Kind = QueryTimeout
+	case strings.HasPrefix(state, "08"), state == "57P01", state == "57P02", state == "57P03":
+		errx.Kind = ConnectionFailure
+	default:
+		return nil
+	}
+
+	errx.Table, _ = fieldOf(err, "TableName", "Table")
+	errx.Column, _ = fieldOf(err, "ColumnName", "Column")
+	errx.Constraint, _ = fieldOf(err, "ConstraintName", "Constraint")
+	return errx
+}
+
+// translateConnection translates the errors of a broken connection.
+func translateConnection(err error) *DatabaseError {
+	var errn net.Error
+
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.As(err, &errn):
+		return &DatabaseError{Kind: ConnectionFailure, Err: err}
+	default:
+		return nil
+	}
+}
+
+// translateMessage translates the driver errors by their messages. It's used
+// when the dialect translator cannot recognize the error (e.g. the driver
+// error is formatted as string by a wrapper).
+func translateMessage(err error) *DatabaseError {
+	var (
+		// error as string
+		errm = err.Error()
+		// known errors
+		errors = [...]struct {
+			kind    ErrorKind
+			message string
+		}{
+			// MySQL 1062 error (ER_DUP_ENTRY).
+			{UniqueViolation, "Error 1062"},
+			// SQLite.
+			{UniqueViolation, "UNIQUE constraint failed"},
+			{ForeignKeyViolation, "FOREIGN KEY constraint failed"},
+			{NotNullViolation, "NOT NULL constraint failed"},
+			{CheckViolation, "CHECK constraint failed"},
+			// PostgreSQL.
+			{UniqueViolation, "duplicate key value violates unique constraint"},
+			{CheckViolation, "violates check constraint"},
+			{ForeignKeyViolation, "violates foreign key constraint"},
+			{NotNullViolation, "violates not-null constraint"},
+			// PostgreSQL 40001 error (serialization_failure).
+			{SerializationFailure, "could not serialize access"},
+			{SerializationFailure, "SQLSTATE 40001"},
+			// PostgreSQL 40P01 error (deadlock_detected).
+			{Deadlock, "deadlock detected"},
+			{Deadlock, "SQLSTATE 40P01"},
+			// MySQL 1213 error (ER_LOCK_DEADLOCK).
+			{Deadlock, "Error 1213"},
+			// MySQL 1205 error (ER_LOCK_WAIT_TIMEOUT).
+			{LockTimeout, "Error 1205"},
+			// SQLite.
+			{LockTimeout, "database is locked"},
+			{LockTimeout, "database table is locked"},
+		}
+	)
+
+	for _, item := range errors {
+		if strings.Contains(errm, item.message) {
+			errx := &DatabaseError{Kind: item.kind, Err: err}
+			// the name of the constraint is usually quoted
+			if _, name, ok := strings.Cut(errm, ` constraint "`); ok {
+				errx.Constraint, _, _ = strings.Cut(name, `"`)
+			}
+			return errx
+		}
+	}
+
+	return nil
+}
+
+// codeOf returns the first integer field with one of the given names of the
+// errors in the chain. The drivers are inspected by reflection to avoid
+// importing them.
+func codeOf(err error, names ...string) (int64, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		value := reflect.Indirect(reflect.ValueOf(err))
+
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+
+		for _, name := range names {
+			field := value.FieldByName(name)
+
+			switch {
+			case !field.IsValid():
+				continue
+			case field.CanInt():
+				return field.Int(), true
+			case field.CanUint():
+				return int64(field.Uint()), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// fieldOf returns the first string field with one of the given names of the
+// errors in the chain.
+func fieldOf(err error, names ...string) (string, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		value := reflect.Indirect(reflect.ValueOf(err))
+
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+
+		for _, name := range names {
+			if field := value.FieldByName(name); field.IsValid() && field.Kind() == reflect.String {
+				return field.String(), true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -1,9 +1,13 @@
 package orm_test
 
 import (
+	"context"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 
 	"github.com/phogolabs/orm"
+	"github.com/phogolabs/orm/dialect"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -105,3 +109,168 @@ var _ = Describe("ConstraintError", func() {
 		})
 	})
 })
+
+type tidb struct {
+	dialect.Dialect
+}
+
+func (tidb) Name() string {
+	return "tidb"
+}
+
+type mysqlError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *mysqlError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+type postgresError struct {
+	Code           string
+	Message        string
+	TableName      string
+	ColumnName     string
+	ConstraintName string
+}
+
+func (e *postgresError) Error() string {
+	return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")"
+}
+
+func (e *postgresError) SQLState() string {
+	return e.Code
+}
+
+var _ = Describe("TranslateError", func() {
+	DescribeTable("translates the error",
+		func(dialect string, err error, kind orm.ErrorKind, table, column, constraint string) {
+			errx := orm.TranslateError(dialect, fmt.Errorf("query: %w", err))
+			Expect(errx).To(MatchError(err))
+			Expect(orm.ErrorKindOf(errx)).To(Equal(kind))
+
+			var derr *orm.DatabaseError
+			Expect(errors.As(errx, &derr)).To(BeTrue())
+			Expect(derr.Table).To(Equal(table))
+			Expect(derr.Column).To(Equal(column))
+			Expect(derr.Constraint).To(Equal(constraint))
+		},
+		Entry("mysql unique violation", "mysql",
+			&mysqlError{Number: 1062, Message: "Duplicate entry 'root' for key 'users.name_idx'"},
+			orm.UniqueViolation, "users", "", "name_idx"),
+		Entry("mysql foreign key violation", "mysql",
+			&mysqlError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`users`, CONSTRAINT `users_group_fk` FOREIGN KEY (`group_id`) REFERENCES `groups` (`id`))"},
+			orm.ForeignKeyViolation, "users", "group_id", "users_group_fk"),
+		Entry("mysql not null violation", "mysql",
+			&mysqlError{Number: 1048, Message: "Column 'name' cannot be null"},
+			orm.NotNullViolation, "", "name", ""),
+		Entry("mysql check violation", "mysql",
+			&mysqlError{Number: 3819, Message: "Check constraint 'users_age_check' is violated."},
+			orm.CheckViolation, "", "", "users_age_check"),
+		Entry("mysql deadlock", "mysql",
+			&mysqlError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+			orm.Deadlock, "", "", ""),
+		Entry("mysql lock timeout", "mysql",
+			&mysqlError{Number: 1205, Message: "Lock wait timeout exceeded"},
+			orm.LockTimeout, "", "", ""),
+		Entry("postgres unique violation", "postgres",
+			&postgresError{Code: "23505", Message: "duplicate key value", TableName: "users", ConstraintName: "users_pkey"},
+			orm.UniqueViolation, "users", "", "users_pkey"),
+		Entry("postgres not null violation", "pgx",
+			&postgresError{Code: "23502", Message: "null value", TableName: "users", ColumnName: "name"},
+			orm.NotNullViolation, "users", "name", ""),
+		Entry("postgres serialization failure", "postgres",
+			&postgresError{Code: "40001", Message: "could not serialize access"},
+			orm.SerializationFailure, "", "", ""),
+		Entry("postgres query timeout", "postgres",
+			&postgresError{Code: "57014", Message: "canceling statement due to statement timeout"},
+			orm.QueryTimeout, "", "", ""),
+		Entry("postgres connection failure", "postgres",
+			&postgresError{Code: "08006", Message: "connection failure"},
+			orm.ConnectionFailure, "", "", ""),
+		Entry("postgres error message", "postgres",
+			errors.New(`pq: duplicate key value violates unique constraint "users_email_key"`),
+			orm.UniqueViolation, "", "", "users_email_key"),
+		Entry("bad connection", "mysql",
+			driver.ErrBadConn,
+			orm.ConnectionFailure, "", "", ""),
+	)
+
+	It("translates the errors of the registered dialects by their family", func() {
+		dialect.Register("tidb", &tidb{dialect.MustLookup(dialect.MySQL)})
+
+		err := orm.TranslateError("tidb", &mysqlError{Number: 1062, Message: "Duplicate entry 'root' for key 'name_idx'"})
+		Expect(orm.IsUniqueViolation(err)).To(BeTrue())
+	})
+
+	It("wraps the constraint violations", func() {
+		err := orm.TranslateError("mysql", &mysqlError{Number: 1062, Message: "Duplicate entry 'root' for key 'name_idx'"})
+		Expect(orm.IsConstraintViolation(err)).To(BeTrue())
+		Expect(orm.IsUniqueViolation(err)).To(BeTrue())
+		Expect(orm.IsForeignKeyViolation(err)).To(BeFalse())
+		Expect(err).To(MatchError("orm: constraint violation: Error 1062: Duplicate entry 'root' for key 'name_idx'"))
+
+		var errx *orm.ConstraintError
+		Expect(errors.As(err, &errx)).To(BeTrue())
+		Expect(errx.Name()).To(Equal("name_idx"))
+	})
+
+	It("classifies the retryable errors", func() {
+		err := orm.TranslateError("postgres", &postgresError{Code: "40P01", Message: "deadlock detected"})
+		Expect(orm.IsRetryable(err)).To(BeTrue())
+		Expect(orm.IsTimeout(err)).To(BeFalse())
+
+		err = orm.TranslateError("mysql", &mysqlError{Number: 1205, Message: "Lock wait timeout exceeded"})
+		Expect(orm.IsRetryable(err)).To(BeTrue())
+		Expect(orm.IsTimeout(err)).To(BeTrue())
+
+		Expect(orm.IsTimeout(fmt.Errorf("query: %w", context.DeadlineExceeded))).To(BeTrue())
+	})
+
+	It("does not translate the error twice", func() {
+		err := orm.TranslateError("mysql", &mysqlError{Number: 1213, Message: "Deadlock found"})
+		Expect(orm.TranslateError("mysql", err)).To(BeIdenticalTo(err))
+	})
+
+	Context("when the error is unknown", func() {
+		It("returns the error", func() {
+			err := errors.New("oh no")
+			Expect(orm.TranslateError("mysql", err)).To(BeIdenticalTo(err))
+			Expect(orm.ErrorKindOf(err)).To(Equal(orm.UnknownError))
+			Expect(orm.TranslateError("mysql", nil)).To(BeNil())
+		})
+	})
+
+	Context("when the dialect has a custom translator", func() {
+		It("uses the translator", func() {
+			orm.RegisterErrorTranslator("custom", orm.ErrorTranslatorFunc(func(err error) *orm.DatabaseError {
+				if err.Error() == "busy" {
+					return &orm.DatabaseError{Kind: orm.LockTimeout, Err: err}
+				}
+				return nil
+			}))
+
+			err := orm.TranslateError("custom-instrumented", errors.New("busy"))
+			Expect(orm.IsRetryable(err)).To(BeTrue())
+			Expect(err).To(MatchError("busy"))
+		})
+	})
+})
+
+var _ = Describe("QueryError", func() {
+	It("returns the error message", func() {
+		errx := &orm.QueryError{Operation: "All", Query: "SELECT * FROM users"}
+		Expect(errx.Error()).To(Equal(`orm: all "SELECT * FROM users"`))
+
+		errx = &orm.QueryError{Operation: "Exec", Routine: "insert-user", Query: "INSERT INTO users VALUES (?)"}
+		Expect(errx.Error()).To(Equal(`orm: exec routine "insert-user"`))
+	})
+
+	Describe("Unwrap", func() {
+		It("returns the wrapped error", func() {
+			errx := &orm.QueryError{}
+			Expect(errx.Unwrap()).To(BeNil())
+		})
+	})
+})
@@ -2,10 +2,14 @@ package orm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/phogolabs/log"
 	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/orm/dialect/sql/scan"
+	"github.com/phogolabs/orm/dialect/sql/schema"
 	"github.com/phogolabs/prana"
 )
 
@@ -13,7 +17,10 @@ var _ Querier = &Gateway{}
 
 // Gateway is connected to a database and can executes SQL queries against it.
 type Gateway struct {
-	engine *engine
+	engine   *engine
+	retry    *RetryPolicy
+	replicas *replicaSet
+	wrappers []func(dialect.Driver) dialect.Driver
 }
 
 // Connect creates a new gateway connecto to the provided URL.
@@ -53,29 +60,49 @@ func Open(name, source string, opts ...Option) (*Gateway, error) {
 			dialect:  dialect,
 			provider: provider,
 		},
+		replicas: &replicaSet{
+			ejection: replicaEjection,
+			done:     make(chan struct{}),
+		},
 	}
 
 	for _, opt := range opts {
 		if err := opt.Apply(gateway); err != nil {
+			// close the primary and the replicas opened by the options
+			gateway.Close()
 			return nil, err
 		}
 	}
 
+	// check the replicas once all options are applied
+	gateway.replicas.start()
+
 	return gateway, nil
 }
 
-// Ping pins the underlying database
+// Ping pins the underlying database. The replicas that fail the ping request
+// are temporarily ejected.
 func (g *Gateway) Ping(ctx context.Context) error {
+	// check the replicas
+	g.replicas.check(ctx)
+
 	driver := g.engine.querier.(dialect.Driver)
 	// make a ping request
 	return driver.Ping(ctx)
 }
 
-// Close closes the connection to the  database.
+// Close closes the connection to the  database and its replicas.
 func (g *Gateway) Close() error {
+	// close the replicas
+	rerr := g.replicas.close()
+
 	driver := g.engine.querier.(dialect.Driver)
 	// close the connection
-	return driver.Close()
+	if err := driver.Close(); err != nil {
+		return err
+	}
+
+	return rerr
 }
 
 // Dialect returns the driver's dialect
@@ -83,41 +110,233 @@ func (g *Gateway) Dialect() string {
 	return g.engine.dialect
 }
 
-// Migrate runs all pending migration
+// Migrate runs all pending migration. The migrations are guarded by a
+// cross-process lock, so the gateways started at once run them only once.
 func (g *Gateway) Migrate(storage FileSystem) error {
 	driver := g.engine.querier.(dialect.Driver)
 	// run the migration
 	return driver.Migrate(storage)
 }
 
+// MigrateTo runs the pending migrations up to the given version and reverts
+// the applied migrations newer than it.
+func (g *Gateway) MigrateTo(storage FileSystem, version string) error {
+	_, err := g.MigrateToWith(storage, version, nil)
+	return err
+}
+
+// MigrateToWith migrates the database to the given version with the given
+// options. It returns the migrations that were run or reverted. In dry-run
+// mode the database is not changed.
+func (g *Gateway) MigrateToWith(storage FileSystem, version string, opts *MigrateOptions) ([]*Migration, error) {
+	driver := g.engine.querier.(dialect.Driver)
+	// run the migration
+	return driver.MigrateTo(storage, version, opts)
+}
+
+// Rollback reverts the given number of applied migrations, starting from the
+// latest. If the steps are negative, it reverts all applied migrations.
+func (g *Gateway) Rollback(storage FileSystem, steps int) error {
+	_, err := g.RollbackWith(storage, steps, nil)
+	return err
+}
+
+// RollbackWith reverts the given number of applied migrations with the given
+// options. It returns the reverted migrations. In dry-run mode the database
+// is not changed.
+func (g *Gateway) RollbackWith(storage FileSystem, steps int, opts *MigrateOptions) ([]*Migration, error) {
+	driver := g.engine.querier.(dialect.Driver)
+	// revert the migration
+	return driver.Rollback(storage, steps, opts)
+}
+
+// MigrationStatus returns all applied and pending migrations ordered by their
+// version.
+func (g *Gateway) MigrationStatus(storage FileSystem) ([]*Migration, error) {
+	driver := g.engine.querier.(dialect.Driver)
+	// read the migrations
+	return driver.MigrationStatus(storage)
+}
+
+// Inspect reads the schema of the database with its tables, columns, indexes
+// and foreign keys.
+func (g *Gateway) Inspect(ctx context.Context) (*schema.Schema, error) {
+	driver := g.engine.querier.(dialect.Driver)
+
+	inspector, err := schema.NewInspector(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return inspector.Inspect(ctx)
+}
+
+// Diff compares the schema of the database with the desired one and returns
+// the plan that migrates the database. The dialect of the gateway is used if
+// the options do not set it.
+func (g *Gateway) Diff(ctx context.Context, desired *schema.Schema, opts *schema.DiffOptions) (*schema.Plan, error) {
+	current, err := g.Inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	options := schema.DiffOptions{}
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Dialect == "" {
+		options.Dialect = g.Dialect()
+	}
+
+	return schema.Diff(current, desired, &options)
+}
+
+// ValidateOptions holds the options of the routine validation
+type ValidateOptions struct {
+	// Prepare prepares each routine against the primary database. The
+	// replicas are not checked, so they should have the same schema.
+	Prepare bool
+}
+
+// ValidateRoutines checks that the named parameters of every routine parse.
+// The routines that have no variant for the dialect of the gateway are
+// skipped.
+func (g *Gateway) ValidateRoutines(ctx context.Context) error {
+	return g.ValidateRoutinesWith(ctx, nil)
+}
+
+// ValidateRoutinesWith checks every routine with the given options. It
+// returns the errors of all invalid routines joined.
+func (g *Gateway) ValidateRoutinesWith(ctx context.Context, opts *ValidateOptions) error {
+	options := ValidateOptions{}
+	if opts != nil {
+		options = *opts
+	}
+
+	var errs []error
+
+	for _, name := range g.engine.provider.NamesOf(g.engine.dialect) {
+		if err := g.validate(ctx, name, &options); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (g *Gateway) validate(ctx context.Context, name string, opts *ValidateOptions) error {
+	info := &QueryInfo{Routine: name}
+
+	query, err := g.engine.provider.QueryOf(name, g.engine.dialect)
+	if err != nil {
+		return g.engine.fail("Validate", info, err)
+	}
+
+	info.Query = query
+
+	_, columns, err := scan.ParseNamedQuery(query, g.engine.dialect)
+	if err != nil {
+		return g.engine.fail("Validate", info, err)
+	}
+
+	if !opts.Prepare {
+		return nil
+	}
+
+	var (
+		args   []interface{}
+		unique = make(map[string]bool)
+	)
+
+	// the arguments are not known, so they are bound to nil
+	for _, column := range columns {
+		if !unique[column] {
+			unique[column] = true
+			args = append(args, sql.Named(column, nil))
+		}
+	}
+
+	query, args, err = g.engine.compile(sql.Routine(name, args...))
+	if err != nil {
+		return g.engine.fail("Validate", info, err)
+	}
+
+	info.Query = query
+	info.Args = args
+
+	driver, ok := g.engine.querier.(dialect.Driver)
+	if !ok {
+		return g.engine.fail("Validate", info, fmt.Errorf("orm: the querier %T cannot prepare the routines", g.engine.querier))
+	}
+	// prepare the routine without executing it
+	return g.engine.fail("Validate", info, g.engine.wrap(dialect.Validate(ctx, driver, query)))
+}
+
 // Begin begins a transaction and returns an *Tx
 func (g *Gateway) Begin(ctx context.Context) (*GatewayTx, error) {
+	return g.BeginTx(ctx, nil)
+}
+
+// BeginTx begins a transaction with the given options and returns an *Tx
+func (g *Gateway) BeginTx(ctx context.Context, opts *TxOptions) (*GatewayTx, error) {
 	driver := g.engine.querier.(dialect.Driver)
 
-	tx, err := driver.Tx(ctx)
+	tx, err := driver.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	gtx := &GatewayTx{
-		engine: &engine{
-			querier:  tx,
-			dialect:  g.engine.dialect,
-			provider: g.engine.provider,
-		},
+		gateway: g,
+		engine:  g.engine.with(tx),
 	}
+	// the transaction context
+	gtx.ctx = SetTxContext(ctx, gtx)
 
 	return gtx, nil
 }
 
 // RunInTx runs a callback function within a transaction. It commits the
-// transaction if succeeds, otherwise rollbacks.
+// transaction if succeeds, otherwise rollbacks. The callback should use the
+// context returned by GatewayTx.Context to run the gateway's queries within
+// the transaction. If the context already carries a transaction started by
+// the gateway, the callback runs in a nested transaction.
 func (g *Gateway) RunInTx(ctx context.Context, fn RunTxFunc) error {
-	logger := log.GetContext(ctx)
+	return g.RunInTxWith(ctx, nil, fn)
+}
+
+// RunInTxWith runs a callback function within a transaction started with
+// the given options. It commits the transaction if succeeds, otherwise
+// rollbacks. The callback is executed again in a new transaction if it fails
+// with an error that is retryable according to the gateway's RetryPolicy.
+func (g *Gateway) RunInTxWith(ctx context.Context, opts *TxOptions, fn RunTxFunc) error {
+	if tx := g.txOf(ctx); tx != nil {
+		return tx.RunInTx(ctx, fn)
+	}
 
-	gtx, err := g.Begin(ctx)
+	for attempt := 1; ; attempt++ {
+		logger := log.GetContext(ctx).WithField("sql.tx.attempt", attempt)
+		// the logger of the attempt is used by the logger driver
+		err := g.runInTx(log.SetContext(ctx, logger), logger, opts, fn)
+		if err == nil || !g.retry.retryable(attempt, err) {
+			return err
+		}
+
+		logger.WithError(err).Warn("tx.retry")
+
+		if werr := g.retry.wait(ctx, attempt); werr != nil {
+			return err
+		}
+	}
+}
+
+// runInTx runs the callback within a transaction. The errors of the driver
+// are translated, but the errors of the callback are returned as they are.
+func (g *Gateway) runInTx(ctx context.Context, logger log.Logger, opts *TxOptions, fn RunTxFunc) error {
+	gtx, err := g.BeginTx(ctx, opts)
 	if err != nil {
-		return err
+		return g.engine.wrap(err)
 	}
 
 	if err := fn(gtx); err != nil {
@@ -130,7 +349,7 @@ func (g *Gateway) RunInTx(ctx context.Context, fn RunTxFunc) error {
 
 	if cerr := gtx.Commit(); cerr != nil {
 		logger.WithError(cerr).Error("cannot commit")
-		return cerr
+		return g.engine.wrap(cerr)
 	}
 
 	return nil
@@ -138,30 +357,116 @@ func (g *Gateway) RunInTx(ctx context.Context, fn RunTxFunc) error {
 
 // All executes the query and returns a list of entities.
 func (g *Gateway) All(ctx context.Context, q sql.Querier, v interface{}) error {
-	return g.engine.All(ctx, q, v)
+	return g.readerOf(ctx).All(ctx, q, v)
 }
 
 // Only returns the only entity in the query, returns an error if not
 // exactly one entity was returned.
 func (g *Gateway) Only(ctx context.Context, q sql.Querier, v interface{}) error {
-	return g.engine.Only(ctx, q, v)
+	return g.readerOf(ctx).Only(ctx, q, v)
 }
 
 // First returns the first entity in the query. Returns *NotFoundError
 // when no records were found.
 func (g *Gateway) First(ctx context.Context, q sql.Querier, v interface{}) error {
-	return g.engine.First(ctx, q, v)
+	return g.readerOf(ctx).First(ctx, q, v)
+}
+
+// Iterate executes the query and returns a cursor that reads the entities
+// one by one. The cursor must be closed by the caller.
+func (g *Gateway) Iterate(ctx context.Context, q sql.Querier) (*Cursor, error) {
+	return g.readerOf(ctx).Iterate(ctx, q)
 }
 
 // Query executes a query that returns rows, typically a SELECT in SQL.
 // It scans the result into the pointer v. In SQL, you it's usually *sql.Rows.
 func (g *Gateway) Query(ctx context.Context, q sql.Querier) (*sql.Rows, error) {
-	return g.engine.Query(ctx, q)
+	return g.readerOf(ctx).Query(ctx, q)
 }
 
 // Exec executes a query that doesn't return rows. For example, in SQL, INSERT
 // or UPDATE.  It scans the result into the pointer v. In SQL, you it's usually
 // sql.Result.
 func (g *Gateway) Exec(ctx context.Context, q sql.Querier) (sql.Result, error) {
-	return g.engine.Exec(ctx, q)
+	return g.engineOf(ctx).Exec(ctx, q)
+}
+
+// Prepare compiles the query and returns a statement that can be executed
+// many times with different named arguments. The arguments are bound to the
+// placeholders created by sql.Param.
+func (g *Gateway) Prepare(ctx context.Context, q sql.Querier) (*Stmt, error) {
+	query, params, err := g.engine.compile(q)
+	if err != nil {
+		return nil, g.engine.fail("Prepare", &QueryInfo{Routine: routineOf(q)}, err)
+	}
+
+	stmt := &Stmt{
+		gateway: g,
+		routine: routineOf(q),
+		query:   query,
+		args:    params,
+	}
+
+	return stmt, nil
+}
+
+// engineOf returns the engine of the transaction carried by the context. It
+// returns the gateway's engine if there is no such transaction.
+func (g *Gateway) engineOf(ctx context.Context) *engine {
+	if tx := g.txOf(ctx); tx != nil {
+		return tx.engine
+	}
+
+	return g.engine
+}
+
+// readerOf returns the engine of a replica that can execute read queries.
+// It returns the engine of the transaction carried by the context or the
+// gateway's engine if there are no available replicas or the context requires
+// the primary database.
+func (g *Gateway) readerOf(ctx context.Context) *engine {
+	if tx := g.txOf(ctx); tx != nil {
+		return tx.engine
+	}
+
+	if isPrimary(ctx) {
+		return g.engine
+	}
+
+	if replica := g.replicas.pick(); replica != nil {
+		return replica.engine
+	}
+
+	return g.engine
+}
+
+// wrap wraps the driver with all driver wrappers configured by the options.
+func (g *Gateway) wrap(driver dialect.Driver) dialect.Driver {
+	for _, fn := range g.wrappers {
+		driver = fn(driver)
+	}
+
+	return driver
+}
+
+// configure wraps the drivers of the gateway and its replicas. The wrapper is
+// applied to the replicas registered later as well.
+func (g *Gateway) configure(fn func(dialect.Driver) dialect.Driver) {
+	g.wrappers = append(g.wrappers, fn)
+	// wrap the primary
+	g.engine.querier = fn(g.engine.querier.(dialect.Driver))
+	// wrap the replicas
+	for _, replica := range g.replicas.items {
+		replica.engine.querier = fn(replica.engine.querier.(dialect.Driver))
+	}
+}
+
+// txOf returns the transaction started by the gateway that is carried by the
+// context.
+func (g *Gateway) txOf(ctx context.Context) *GatewayTx {
+	if tx := GetTxContext(ctx); tx != nil && tx.gateway == g {
+		return tx
+	}
+
+	return nil
 }
@@ -2,42 +2,54 @@ package orm
 
 import (
 	"context"
-	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/go-openapi/inflect"
 	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql"
 	"github.com/phogolabs/orm/dialect/sql/scan"
-	"github.com/phogolabs/prana/sqlexec"
 )
 
 var _ Querier = &engine{}
 
 type engine struct {
-	provider *sqlexec.Provider
-	querier  dialect.ExecQuerier
-	dialect  string
+	provider     *sql.Provider
+	querier      dialect.ExecQuerier
+	dialect      string
+	interceptors []Interceptor
+	redact       bool
+}
+
+// with returns a copy of the engine that uses the given querier.
+func (g *engine) with(querier dialect.ExecQuerier) *engine {
+	return &engine{
+		provider:     g.provider,
+		querier:      querier,
+		dialect:      g.dialect,
+		interceptors: slices.Clip(g.interceptors),
+		redact:       g.redact,
+	}
 }
 
 // All executes the query and returns a list of entities.
 func (g *engine) All(ctx context.Context, q sql.Querier, v interface{}) error {
-	rows, err := g.Query(ctx, q)
+	info, err := g.query(ctx, q)
 	if err != nil {
-		return err
+		return g.fail("All", info, err)
 	}
 	// close the rows
-	defer rows.Close()
+	defer info.Rows.Close()
 
 	// scan the rows into the target
-	if err := scan.Rows(rows, v); err != nil {
-		return g.wrap(err)
+	if err := scan.Rows(info.Rows, v); err != nil {
+		return g.fail("All", info, g.wrap(err))
 	}
 
 	// if the query supports scannable interface
 	if scanner, ok := q.(scan.Readable); ok {
-		return scanner.Scan(v)
+		return g.fail("All", info, scanner.Scan(v))
 	}
 
 	return nil
@@ -46,96 +58,205 @@ func (g *engine) All(ctx context.Context, q sql.Querier, v interface{}) error {
 // Only returns the only entity in the query, returns an error if not
 // exactly one entity was returned.
 func (g *engine) Only(ctx context.Context, q sql.Querier, v interface{}) error {
-	rows, err := g.Query(ctx, q)
+	info, err := g.query(ctx, q)
 	if err != nil {
-		return err
+		return g.fail("Only", info, err)
 	}
 	// close the rows
-	defer rows.Close()
+	defer info.Rows.Close()
 
 	// scan the rows into the target
-	err = scan.Row(rows, v)
+	err = scan.Row(info.Rows, v)
 
 	switch {
 	case err == sql.ErrNoRows:
-		return &NotFoundError{nameOf(reflect.TypeOf(v))}
+		return g.fail("Only", info, &NotFoundError{nameOf(reflect.TypeOf(v))})
 	case err == scan.ErrOneRow:
-		return &NotSingularError{nameOf(reflect.TypeOf(v))}
+		return g.fail("Only", info, &NotSingularError{nameOf(reflect.TypeOf(v))})
 	default:
-		return g.wrap(err)
+		return g.fail("Only", info, g.wrap(err))
 	}
 }
 
 // First returns the first entity in the query. Returns *NotFoundError
 // when no user was found.
 func (g *engine) First(ctx context.Context, q sql.Querier, v interface{}) error {
-	rows, err := g.Query(ctx, q)
+	info, err := g.query(ctx, q)
 	if err != nil {
-		return g.wrap(err)
+		return g.fail("First", info, err)
 	}
 	// close the rows
-	defer rows.Close()
+	defer info.Rows.Close()
 
 	// scan the rows into the target
-	err = scan.Row(rows, v)
+	err = scan.Row(info.Rows, v)
 
 	switch {
 	case err == sql.ErrNoRows:
-		return &NotFoundError{nameOf(reflect.TypeOf(v))}
+		return g.fail("First", info, &NotFoundError{nameOf(reflect.TypeOf(v))})
 	case err == scan.ErrOneRow:
 		return nil
 	default:
-		return g.wrap(err)
+		return g.fail("First", info, g.wrap(err))
+	}
+}
+
+// Iterate executes the query and returns a cursor that reads the entities
+// one by one. The cursor must be closed by the caller.
+func (g *engine) Iterate(ctx context.Context, q sql.Querier) (*Cursor, error) {
+	info, err := g.query(ctx, q)
+	if err != nil {
+		return nil, g.fail("Iterate", info, err)
+	}
+
+	cursor, err := scan.NewCursor(info.Rows)
+	if err != nil {
+		info.Rows.Close()
+		return nil, g.fail("Iterate", info, g.wrap(err))
 	}
+
+	return &Cursor{rows: info.Rows, cursor: cursor}, nil
 }
 
 // Query executes a query that returns rows, typically a SELECT in SQL.
 // It scans the result into the pointer v. In SQL, you it's usually *sql.Rows.
 func (g *engine) Query(ctx context.Context, q sql.Querier) (*sql.Rows, error) {
-	// compile the query prior to execution
-	query, params, err := g.compile(q)
+	info, err := g.query(ctx, q)
 	if err != nil {
-		return nil, g.wrap(err)
-	}
-
-	rows := &sql.Rows{}
-	// execute the query into the rows
-	if err := g.querier.Query(ctx, query, params, rows); err != nil {
-		return nil, g.wrap(err)
+		return nil, g.fail("Query", info, err)
 	}
 
-	return rows, nil
+	return info.Rows, nil
 }
 
 // Exec executes a query that doesn't return rows. For example, in SQL, INSERT
 // or UPDATE.  It scans the result into the pointer v. In SQL, you it's usually
 // sql.Result.
 func (g *engine) Exec(ctx context.Context, q sql.Querier) (sql.Result, error) {
+	info, err := g.exec(ctx, q)
+	if err != nil {
+		return nil, g.fail("Exec", info, err)
+	}
+
+	return info.Result, nil
+}
+
+// query compiles and executes the query. It returns the info of the query
+// even if the execution fails.
+func (g *engine) query(ctx context.Context, q sql.Querier) (*QueryInfo, error) {
+	info := &QueryInfo{
+		Routine: routineOf(q),
+	}
+
+	var err error
 	// compile the query prior to execution
-	query, params, err := g.compile(q)
+	info.Query, info.Args, err = g.compile(q)
 	if err != nil {
-		return nil, g.wrap(err)
+		return info, g.wrap(err)
 	}
 
-	var result sql.Result
-	// execute the query into the reslt
-	if err := g.querier.Exec(ctx, query, params, &result); err != nil {
-		return nil, g.wrap(err)
+	handler := func(ctx context.Context, info *QueryInfo) error {
+		if info.Routine != "" {
+			ctx = dialect.SetRoutineContext(ctx, info.Routine)
+		}
+
+		rows := &sql.Rows{}
+		// execute the query into the rows
+		// the database receives the original values of the sensitive arguments
+		args, positions := dialect.Reveal(info.Args)
+		if len(positions) > 0 {
+			ctx = dialect.SetSensitiveContext(ctx, positions)
+		}
+
+		if err := g.querier.Query(ctx, info.Query, args, rows); err != nil {
+			return err
+		}
+
+		info.Rows = rows
+		return nil
+	}
+
+	if err := g.intercept(ctx, info, handler); err != nil {
+		return info, g.wrap(err)
 	}
 
-	return result, nil
+	return info, nil
 }
 
-func (g *engine) compile(stmt sql.Querier) (string, []interface{}, error) {
-	type QuerierRoutine interface {
-		Name() string
-		SetQuery(string)
+// exec compiles and executes the query that doesn't return rows. It returns
+// the info of the query even if the execution fails.
+func (g *engine) exec(ctx context.Context, q sql.Querier) (*QueryInfo, error) {
+	info := &QueryInfo{
+		Routine: routineOf(q),
+	}
+
+	var err error
+	// compile the query prior to execution
+	info.Query, info.Args, err = g.compile(q)
+	if err != nil {
+		return info, g.wrap(err)
 	}
 
+	handler := func(ctx context.Context, info *QueryInfo) error {
+		if info.Routine != "" {
+			ctx = dialect.SetRoutineContext(ctx, info.Routine)
+		}
+
+		var result sql.Result
+		// execute the query into the reslt
+		// the database receives the original values of the sensitive arguments
+		args, positions := dialect.Reveal(info.Args)
+		if len(positions) > 0 {
+			ctx = dialect.SetSensitiveContext(ctx, positions)
+		}
+
+		if err := g.querier.Exec(ctx, info.Query, args, &result); err != nil {
+			return err
+		}
+
+		info.Result = result
+		return nil
+	}
+
+	if err := g.intercept(ctx, info, handler); err != nil {
+		return info, g.wrap(err)
+	}
+
+	return info, nil
+}
+
+// fail wraps the error of the operation with the info of the query.
+func (g *engine) fail(operation string, info *QueryInfo, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	errx := &QueryError{
+		Operation: operation,
+		Routine:   info.Routine,
+		Query:     info.Query,
+		NumArgs:   len(info.Args),
+		err:       err,
+	}
+
+	if g.redact {
+		errx.Query = ""
+	}
+
+	return errx
+}
+
+// querierRoutine represents a query that is loaded from the routine provider.
+type querierRoutine interface {
+	Name() string
+	SetQuery(string)
+}
+
+func (g *engine) compile(stmt sql.Querier) (string, []interface{}, error) {
 	// find the command if any
-	if routine, ok := stmt.(QuerierRoutine); ok {
-		// get the actual SQL query
-		query, err := g.provider.Query(routine.Name())
+	if routine, ok := stmt.(querierRoutine); ok {
+		// get the variant of the routine for the dialect
+		query, err := g.provider.QueryOf(routine.Name(), g.dialect)
 		// if getting the query fails
 		if err != nil {
 			return "", nil, g.wrap(err)
@@ -171,44 +292,18 @@ func (g *engine) compile(stmt sql.Querier) (string, []interface{}, error) {
 }
 
 func (g *engine) wrap(err error) error {
-	if err == nil {
-		return nil
-	}
+	return TranslateError(g.dialect, err)
+}
 
-	var (
-		// error as string
-		errm = err.Error()
-		// known errors
-		errors = [...]string{
-			// MySQL 1062 error (ER_DUP_ENTRY).
-			"Error 1062",
-			// SQLite.
-			"UNIQUE constraint failed: %s",
-			// PostgreSQL.
-			"pq: duplicate key value violates unique constraint %q",
-			// check constraint
-			"pq: violates check constraint %q",
-			// new row check constraint
-			"pq: new row for relation %q violates check constraint %q",
-		}
-	)
-
-	for _, message := range errors {
-		// name of the constrain
-		var name string
-		// scane the name
-		fmt.Sscanf(errm, message, &name, &name)
-		// check
-		if len(name) > 0 || strings.Contains(errm, message) {
-			// return the constraint
-			return &ConstraintError{
-				name: name,
-				wrap: err,
-			}
-		}
+func routineOf(stmt sql.Querier) string {
+	switch routine := stmt.(type) {
+	case querierRoutine:
+		return routine.Name()
+	case *stmtQuery:
+		return routine.stmt.routine
+	default:
+		return ""
 	}
-
-	return err
 }
 
 func nameOf(value reflect.Type) string {
@@ -0,0 +1,49 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// QueryInfo describes a query executed by the gateway.
+type QueryInfo struct {
+	// Routine is the name of the routine. It's empty if the query is not a
+	// routine.
+	Routine string
+	// Query is the compiled SQL query.
+	Query string
+	// Args are the arguments of the query.
+	Args []interface{}
+	// Rows are the rows returned by the query. It's set after the query is
+	// executed by Query.
+	Rows *sql.Rows
+	// Result is the result of the query. It's set after the query is executed
+	// by Exec.
+	Result sql.Result
+}
+
+// Handler executes the query described by the info.
+type Handler func(ctx context.Context, info *QueryInfo) error
+
+// Interceptor intercepts the execution of a query. It must call next to
+// execute the query. It may change the query and its arguments before that
+// or inspect the result and the error afterwards.
+type Interceptor func(ctx context.Context, info *QueryInfo, next Handler) error
+
+// intercept executes the handler wrapped by all interceptors. The first
+// interceptor is the outermost one.
+func (g *engine) intercept(ctx context.Context, info *QueryInfo, handler Handler) error {
+	for index := len(g.interceptors) - 1; index >= 0; index-- {
+		var (
+			interceptor = g.interceptors[index]
+			next        = handler
+		)
+
+		handler = func(ctx context.Context, info *QueryInfo) error {
+			return interceptor(ctx, info, next)
+		}
+	}
+
+	return handler(ctx, info)
+}
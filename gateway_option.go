@@ -5,6 +5,7 @@ import (
 
 	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/prana"
 )
 
 // Option represents a Gateway option
@@ -23,9 +24,35 @@ func (fn OptionFunc) Apply(gateway *Gateway) error {
 // WithLogger sets the logger
 func WithLogger(logger dialect.Logger) Option {
 	fn := func(g *Gateway) error {
-		if driver, ok := g.engine.querier.(*sql.Driver); ok {
-			g.engine.querier = dialect.Log(driver, logger)
-		}
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			return dialect.Log(driver, logger)
+		})
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithLoggerConfig sets the logger that logs the statements according to the
+// given configuration.
+func WithLoggerConfig(logger dialect.Logger, config *dialect.LoggerConfig) Option {
+	fn := func(g *Gateway) error {
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			return dialect.LogWith(driver, logger, config)
+		})
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithMetrics reports the metrics of all statements executed by the gateway,
+// its replicas and transactions to the given collector.
+func WithMetrics(collector dialect.MetricsCollector) Option {
+	fn := func(g *Gateway) error {
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			return dialect.Metrics(driver, collector)
+		})
 		return nil
 	}
 
@@ -44,9 +71,12 @@ func WithLogger(logger dialect.Logger) Option {
 // a future release.
 func WithMaxIdleConns(value int) Option {
 	fn := func(g *Gateway) error {
-		if driver, ok := g.engine.querier.(*sql.Driver); ok {
-			driver.DB().SetMaxIdleConns(value)
-		}
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			if driver, ok := baseOf(driver); ok {
+				driver.DB().SetMaxIdleConns(value)
+			}
+			return driver
+		})
 		return nil
 	}
 
@@ -63,9 +93,12 @@ func WithMaxIdleConns(value int) Option {
 // The default is 0 (unlimited).
 func WithMaxOpenConns(value int) Option {
 	fn := func(g *Gateway) error {
-		if driver, ok := g.engine.querier.(*sql.Driver); ok {
-			driver.DB().SetMaxOpenConns(value)
-		}
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			if driver, ok := baseOf(driver); ok {
+				driver.DB().SetMaxOpenConns(value)
+			}
+			return driver
+		})
 		return nil
 	}
 
@@ -79,16 +112,41 @@ func WithMaxOpenConns(value int) Option {
 // If d <= 0, connections are reused forever.
 func WithConnMaxLifetime(duration time.Duration) Option {
 	fn := func(g *Gateway) error {
-		if driver, ok := g.engine.querier.(*sql.Driver); ok {
-			driver.DB().SetConnMaxLifetime(duration)
-		}
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			if driver, ok := baseOf(driver); ok {
+				driver.DB().SetConnMaxLifetime(duration)
+			}
+			return driver
+		})
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithStmtCacheSize sets the maximum number of prepared statements cached by
+// the gateway and its replicas. The statements are prepared on first use
+// and reused for the queries with the same SQL text.
+//
+// If n <= 0, the statements are not cached. The cache is disabled by default.
+func WithStmtCacheSize(size int) Option {
+	fn := func(g *Gateway) error {
+		g.configure(func(driver dialect.Driver) dialect.Driver {
+			if driver, ok := baseOf(driver); ok {
+				driver.SetStmtCacheSize(size)
+			}
+			return driver
+		})
 		return nil
 	}
 
 	return OptionFunc(fn)
 }
 
-// WithRoutine creates the gateway with a given routine
+// WithRoutine creates the gateway with a given routine. The routines may
+// have a variant for each dialect (e.g. routine.postgres.sql or a
+// `-- dialect: postgres` annotation). The variant of the gateway's dialect is
+// executed.
 func WithRoutine(source FileSystem) Option {
 	fn := func(g *Gateway) error {
 		if err := g.engine.provider.ReadDir(source); err != nil {
@@ -99,3 +157,119 @@ func WithRoutine(source FileSystem) Option {
 
 	return OptionFunc(fn)
 }
+
+// WithRetryPolicy sets the policy used by RunInTx and RunInTxWith to retry the
+// transactions that fail with retryable errors like serialization failures
+// and deadlocks.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	fn := func(g *Gateway) error {
+		g.retry = policy
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithInterceptor adds an interceptor that wraps the execution of every query
+// run by the gateway, its replicas and transactions. The interceptors are
+// executed in the order they were added.
+func WithInterceptor(interceptor Interceptor) Option {
+	fn := func(g *Gateway) error {
+		g.engine.interceptors = append(g.engine.interceptors, interceptor)
+
+		for _, replica := range g.replicas.items {
+			replica.engine.interceptors = append(replica.engine.interceptors, interceptor)
+		}
+
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithRedactedErrors strips the SQL query from the QueryError returned by the
+// gateway. The errors still carry the routine name and the argument count.
+// It's usually used in production to avoid leaking the queries in the logs.
+func WithRedactedErrors() Option {
+	fn := func(g *Gateway) error {
+		g.engine.redact = true
+
+		for _, replica := range g.replicas.items {
+			replica.engine.redact = true
+		}
+
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithReplica registers a read replica for the given URL. The read queries
+// executed by All, First, Only, Iterate and Query are routed to the replicas.
+// Exec and the transactions always use the primary database.
+func WithReplica(url string) Option {
+	fn := func(g *Gateway) error {
+		name, source, err := prana.ParseURL(url)
+		if err != nil {
+			return err
+		}
+
+		driver, err := sql.Open(name, source)
+		if err != nil {
+			return err
+		}
+
+		item := &replica{
+			driver: driver,
+			engine: g.engine.with(g.wrap(driver)),
+		}
+
+		g.replicas.items = append(g.replicas.items, item)
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithReplicaPolicy sets the policy that selects the replica for the read
+// queries. The default policy is RoundRobin.
+func WithReplicaPolicy(policy ReplicaPolicy) Option {
+	fn := func(g *Gateway) error {
+		g.replicas.policy = policy
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// WithReplicaHealthCheck pings the replicas on every interval. It sets only
+// the interval of the checks. The replicas that fail the ping request are
+// ejected for 30 seconds or until a later check succeeds. The checks start
+// when the gateway is opened with all options.
+func WithReplicaHealthCheck(interval time.Duration) Option {
+	fn := func(g *Gateway) error {
+		g.replicas.interval = interval
+		return nil
+	}
+
+	return OptionFunc(fn)
+}
+
+// baseOf returns the sql driver wrapped by the given driver (e.g. by the
+// logger or the metrics driver).
+func baseOf(driver dialect.Driver) (*sql.Driver, bool) {
+	type Wrapper interface {
+		Unwrap() dialect.Driver
+	}
+
+	for {
+		switch value := driver.(type) {
+		case *sql.Driver:
+			return value, true
+		case Wrapper:
+			driver = value.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package orm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// ReplicaPolicy represents the policy that selects the replica which
+// executes a read query.
+type ReplicaPolicy int
+
+const (
+	// RoundRobin selects the replicas in turns.
+	RoundRobin ReplicaPolicy = iota
+	// LeastConnections selects the replica with the least connections in use.
+	LeastConnections
+)
+
+// replicaEjection is the default duration for which a replica that fails the
+// ping request does not receive any queries.
+const replicaEjection = 30 * time.Second
+
+type replica struct {
+	driver  *sql.Driver
+	engine  *engine
+	ejected atomic.Int64
+}
+
+// available reports whether the replica can receive queries.
+func (r *replica) available(now time.Time) bool {
+	return r.ejected.Load() <= now.UnixNano()
+}
+
+// check pings the replica and ejects it for the given duration when the ping
+// fails.
+func (r *replica) check(ctx context.Context, ejection time.Duration) {
+	if err := r.driver.Ping(ctx); err != nil {
+		r.ejected.Store(time.Now().Add(ejection).UnixNano())
+		return
+	}
+
+	r.ejected.Store(0)
+}
+
+type replicaSet struct {
+	policy   ReplicaPolicy
+	ejection time.Duration
+	interval time.Duration
+	items    []*replica
+	next     atomic.Uint64
+	once     sync.Once
+	done     chan struct{}
+}
+
+// pick selects an available replica according to the policy. It returns nil
+// if there are no available replicas.
+func (s *replicaSet) pick() *replica {
+	var (
+		now       = time.Now()
+		count     = uint64(len(s.items))
+		candidate *replica
+	)
+
+	for index := uint64(0); index < count; index++ {
+		switch s.policy {
+		case LeastConnections:
+			item := s.items[index]
+
+			if !item.available(now) {
+				continue
+			}
+
+			if candidate == nil || item.driver.DB().Stats().InUse < candidate.driver.DB().Stats().InUse {
+				candidate = item
+			}
+		default:
+			item := s.items[s.next.Add(1)%count]
+
+			if item.available(now) {
+				return item
+			}
+		}
+	}
+
+	return candidate
+}
+
+// check pings all replicas and ejects the ones that fail.
+func (s *replicaSet) check(ctx context.Context) {
+	group := &sync.WaitGroup{}
+
+	for _, item := range s.items {
+		group.Add(1)
+
+		go func(item *replica) {
+			defer group.Done()
+			item.check(ctx, s.ejection)
+		}(item)
+	}
+
+	group.Wait()
+}
+
+// start starts the health check of the replicas if its interval is set.
+func (s *replicaSet) start() {
+	if s.interval > 0 {
+		go s.watch(s.interval)
+	}
+}
+
+// watch checks the replicas on every interval until the set is closed.
+func (s *replicaSet) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.check(context.Background())
+		}
+	}
+}
+
+// close closes all replicas.
+func (s *replicaSet) close() error {
+	var err error
+
+	s.once.Do(func() {
+		close(s.done)
+
+		for _, item := range s.items {
+			if cerr := item.driver.Close(); err == nil {
+				err = cerr
+			}
+		}
+	})
+
+	return err
+}
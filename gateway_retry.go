@@ -0,0 +1,70 @@
+package orm
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy configures how a transaction is retried when it fails with a
+// retryable error like serialization failure or deadlock.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// MinBackoff is the backoff before the second attempt. It's doubled on
+	// every next attempt.
+	MinBackoff time.Duration
+	// MaxBackoff is the upper limit of the backoff.
+	MaxBackoff time.Duration
+	// Retryable reports whether the error is retryable. If nil, the errors
+	// classified by IsRetryable are retried.
+	Retryable func(error) bool
+}
+
+// retryable reports whether the transaction should be retried after the
+// given attempt failed with err.
+func (p *RetryPolicy) retryable(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	return IsRetryable(err)
+}
+
+// backoff returns the exponential backoff with jitter for the given attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinBackoff
+
+	for index := 1; index < attempt && delay < p.MaxBackoff; index++ {
+		delay = delay * 2
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if half := int64(delay / 2); half > 0 {
+		// the jitter is in the range of [delay/2, delay)
+		delay = time.Duration(half + rand.Int64N(half))
+	}
+
+	return delay
+}
+
+// wait blocks until the backoff for the given attempt elapses or the context
+// is done.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
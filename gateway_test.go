@@ -2,11 +2,20 @@ package orm_test
 
 import (
 	"context"
+	stdsql "database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"testing/fstest"
+	"time"
 
 	"github.com/go-faker/faker/v4"
+	"github.com/phogolabs/log"
+	"github.com/phogolabs/log/fake"
 	"github.com/phogolabs/orm"
+	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql"
+	"github.com/phogolabs/orm/dialect/sql/schema"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -21,6 +30,18 @@ var _ = Describe("Open", func() {
 		})
 	})
 
+	Context("when an option fails", func() {
+		It("returns an error", func() {
+			gateway, err := orm.Open("sqlite3", "file:open.db?cache=shared&mode=memory",
+				orm.WithReplicaHealthCheck(time.Hour),
+				orm.WithReplica("sqlite3://file:replica.db?cache=shared&mode=memory"),
+				orm.WithReplica("replica"),
+			)
+			Expect(gateway).To(BeNil())
+			Expect(err).To(MatchError("invalid dsn"))
+		})
+	})
+
 	Context("when the dns is not wrong", func() {
 		It("returns an error", func() {
 			gateway, err := orm.Open("mysql", "localhost")
@@ -171,13 +192,892 @@ var _ = Describe("Gateway", func() {
 		})
 	})
 
+	Describe("Context", func() {
+		count := func(ctx context.Context) int {
+			value, err := orm.Scalar[int](ctx, gateway, sql.Raw("SELECT COUNT(*) FROM users"))
+			Expect(err).To(Succeed())
+			return value
+		}
+
+		It("runs the queries within the transaction", func() {
+			err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+				ctx := tx.Context()
+				Expect(orm.GetTxContext(ctx)).To(Equal(tx))
+
+				_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users"))
+				Expect(err).To(Succeed())
+				Expect(count(ctx)).To(Equal(0))
+
+				return fmt.Errorf("oh no")
+			})
+
+			Expect(err).To(MatchError("oh no"))
+			Expect(count(ctx)).To(Equal(10))
+		})
+
+		It("runs the nested transaction within the transaction", func() {
+			err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+				ctx := tx.Context()
+
+				_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+				Expect(err).To(Succeed())
+
+				err = gateway.RunInTx(ctx, func(ntx *orm.GatewayTx) error {
+					Expect(ntx).To(Equal(tx))
+
+					_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users"))
+					Expect(err).To(Succeed())
+					return fmt.Errorf("oh no")
+				})
+
+				Expect(err).To(MatchError("oh no"))
+				Expect(count(ctx)).To(Equal(9))
+				return nil
+			})
+
+			Expect(err).To(Succeed())
+			Expect(count(ctx)).To(Equal(9))
+		})
+
+		Context("when the context is detached from the transaction", func() {
+			It("runs the queries outside of the transaction", func() {
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					ctx := orm.WithoutTx(tx.Context())
+					Expect(orm.GetTxContext(ctx)).To(BeNil())
+
+					_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+					Expect(err).To(Succeed())
+
+					return fmt.Errorf("oh no")
+				})
+
+				Expect(err).To(MatchError("oh no"))
+				Expect(count(ctx)).To(Equal(9))
+			})
+		})
+
+		Context("when the transaction is started by another gateway", func() {
+			It("runs the queries outside of the transaction", func() {
+				other, err := orm.Open("sqlite3", "file:test.db?cache=shared&mode=memory")
+				Expect(err).To(BeNil())
+				defer other.Close()
+
+				err = other.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					_, err := gateway.Exec(tx.Context(), sql.Raw("DELETE FROM users WHERE id = 0"))
+					Expect(err).To(Succeed())
+
+					return fmt.Errorf("oh no")
+				})
+
+				Expect(err).To(MatchError("oh no"))
+				Expect(count(ctx)).To(Equal(9))
+			})
+		})
+	})
+
+	Describe("RunInTxWith", func() {
+		It("starts new transaction with the options", func() {
+			opts := &orm.TxOptions{
+				Isolation: stdsql.LevelSerializable,
+			}
+
+			err := gateway.RunInTxWith(ctx, opts, func(tx *orm.GatewayTx) error {
+				_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+				return err
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the begin statement is provided", func() {
+			It("starts new transaction", func() {
+				opts := &orm.TxOptions{
+					Begin:      "BEGIN IMMEDIATE",
+					Statements: []string{"PRAGMA defer_foreign_keys = ON"},
+				}
+
+				err := gateway.RunInTxWith(ctx, opts, func(tx *orm.GatewayTx) error {
+					_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+					return err
+				})
+
+				Expect(err).NotTo(HaveOccurred())
+
+				entities := []*User{}
+				Expect(gateway.All(ctx, sql.Raw("SELECT * FROM users"), &entities)).To(Succeed())
+				Expect(entities).To(HaveLen(9))
+			})
+
+			It("rollbacks the transaction", func() {
+				opts := &orm.TxOptions{
+					Begin: "BEGIN IMMEDIATE",
+				}
+
+				err := gateway.RunInTxWith(ctx, opts, func(tx *orm.GatewayTx) error {
+					_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users"))
+					Expect(err).To(Succeed())
+					return fmt.Errorf("oh no")
+				})
+
+				Expect(err).To(MatchError("oh no"))
+
+				entities := []*User{}
+				Expect(gateway.All(ctx, sql.Raw("SELECT * FROM users"), &entities)).To(Succeed())
+				Expect(entities).To(HaveLen(10))
+			})
+
+			Context("when the isolation level is provided", func() {
+				It("returns an error", func() {
+					opts := &orm.TxOptions{
+						Begin:    "BEGIN IMMEDIATE",
+						ReadOnly: true,
+					}
+
+					err := gateway.RunInTxWith(ctx, opts, func(tx *orm.GatewayTx) error {
+						return nil
+					})
+
+					Expect(err).To(MatchError(`dialect/sql: isolation level and read-only mode cannot be combined with "BEGIN IMMEDIATE"`))
+				})
+			})
+		})
+
+		Context("when a statement fails", func() {
+			It("returns an error", func() {
+				opts := &orm.TxOptions{
+					Statements: []string{"SET LOCAL statement_timeout = 100"},
+				}
+
+				err := gateway.RunInTxWith(ctx, opts, func(tx *orm.GatewayTx) error {
+					return nil
+				})
+
+				Expect(err).To(MatchError(`near "SET": syntax error`))
+			})
+		})
+	})
+
+	Describe("WithLogger", func() {
+		It("masks the sensitive values only in the logs", func() {
+			type Account struct {
+				ID        int    `db:"id,primary_key"`
+				FirstName string `db:"first_name"`
+				LastName  string `db:"last_name"`
+				Email     string `db:"email,sensitive"`
+			}
+
+			handler := &fake.Handler{}
+			Expect(orm.WithLogger(log.New(&log.Config{Handler: handler})).Apply(gateway)).To(Succeed())
+
+			account := &Account{ID: 100, FirstName: "Jack", LastName: "Doe", Email: "jack@example.com"}
+
+			_, err := gateway.Exec(ctx, sql.NewInsert("users").Entity(account))
+			Expect(err).To(Succeed())
+
+			Expect(handler.HandleCallCount()).To(Equal(1))
+			Expect(handler.HandleArgsForCall(0).Fields).To(HaveKeyWithValue("sql.param", []interface{}{100, "Jack", "Doe", "[REDACTED]"}))
+
+			entity := &User{}
+			Expect(gateway.First(ctx, sql.Select().From(sql.Table("users")).Where(sql.EQ("id", 100)), entity)).To(Succeed())
+			Expect(*entity.Email).To(Equal("jack@example.com"))
+		})
+	})
+
+	Describe("WithRetryPolicy", func() {
+		var attempts int
+
+		deadlock := func() error {
+			return &orm.DatabaseError{
+				Kind: orm.Deadlock,
+				Err:  fmt.Errorf("Error 1213: Deadlock found when trying to get lock"),
+			}
+		}
+
+		BeforeEach(func() {
+			attempts = 0
+
+			policy := &orm.RetryPolicy{
+				MaxAttempts: 3,
+				MinBackoff:  time.Millisecond,
+				MaxBackoff:  5 * time.Millisecond,
+			}
+
+			Expect(orm.WithRetryPolicy(policy).Apply(gateway)).To(Succeed())
+		})
+
+		It("retries the transaction", func() {
+			err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+				_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+				Expect(err).To(Succeed())
+
+				if attempts++; attempts < 3 {
+					return deadlock()
+				}
+
+				return nil
+			})
+
+			Expect(err).To(Succeed())
+			Expect(attempts).To(Equal(3))
+		})
+
+		Context("when the attempts are exhausted", func() {
+			It("returns an error", func() {
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					attempts++
+					return deadlock()
+				})
+
+				Expect(err).To(MatchError("Error 1213: Deadlock found when trying to get lock"))
+				Expect(attempts).To(Equal(3))
+			})
+		})
+
+		Context("when the error is not retryable", func() {
+			It("returns an error", func() {
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					attempts++
+					return fmt.Errorf("oh no")
+				})
+
+				Expect(err).To(MatchError("oh no"))
+				Expect(attempts).To(Equal(1))
+			})
+		})
+
+		Context("when the error of the callback looks like a driver error", func() {
+			It("does not translate it", func() {
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					attempts++
+					return fmt.Errorf("database is locked")
+				})
+
+				Expect(err).To(MatchError("database is locked"))
+				Expect(orm.ErrorKindOf(err)).To(Equal(orm.UnknownError))
+				Expect(attempts).To(Equal(1))
+			})
+		})
+
+		It("logs the attempt of the queries", func() {
+			handler := &fake.Handler{}
+			Expect(orm.WithLogger(log.New(&log.Config{Handler: handler})).Apply(gateway)).To(Succeed())
+
+			err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+				_, err := tx.Exec(tx.Context(), sql.Raw("DELETE FROM users WHERE id = 0"))
+				Expect(err).To(Succeed())
+
+				if attempts++; attempts < 2 {
+					return deadlock()
+				}
+
+				return nil
+			})
+
+			Expect(err).To(Succeed())
+
+			var values []interface{}
+
+			for index := 0; index < handler.HandleCallCount(); index++ {
+				if entry := handler.HandleArgsForCall(index); entry.Message == "query.exec success" {
+					values = append(values, entry.Fields["sql.tx.attempt"])
+				}
+			}
+
+			Expect(values).To(Equal([]interface{}{1, 2}))
+		})
+
+		Context("when the classifier is provided", func() {
+			It("retries the transaction", func() {
+				policy := &orm.RetryPolicy{
+					MaxAttempts: 2,
+					Retryable: func(err error) bool {
+						return err.Error() == "oh no"
+					},
+				}
+
+				Expect(orm.WithRetryPolicy(policy).Apply(gateway)).To(Succeed())
+
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					attempts++
+					return fmt.Errorf("oh no")
+				})
+
+				Expect(err).To(MatchError("oh no"))
+				Expect(attempts).To(Equal(2))
+			})
+		})
+
+		Context("when the context is canceled", func() {
+			It("returns an error", func() {
+				ctx, cancel := context.WithCancel(ctx)
+
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					attempts++
+					cancel()
+					return deadlock()
+				})
+
+				Expect(err).To(MatchError("Error 1213: Deadlock found when trying to get lock"))
+				Expect(attempts).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("GatewayTx", func() {
+		count := func(querier orm.Querier) int {
+			value, err := orm.Scalar[int](ctx, querier, sql.Raw("SELECT COUNT(*) FROM users"))
+			Expect(err).To(Succeed())
+			return value
+		}
+
+		Describe("RunInTx", func() {
+			It("releases the savepoint", func() {
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					return tx.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+						_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+						return err
+					})
+				})
+
+				Expect(err).To(Succeed())
+				Expect(count(gateway)).To(Equal(9))
+			})
+
+			Context("when the nested transaction fails", func() {
+				It("rollbacks only the savepoint", func() {
+					err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+						_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+						Expect(err).To(Succeed())
+
+						err = tx.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+							_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users"))
+							Expect(err).To(Succeed())
+							Expect(count(tx)).To(Equal(0))
+							return fmt.Errorf("oh no")
+						})
+
+						Expect(err).To(MatchError("oh no"))
+						Expect(count(tx)).To(Equal(9))
+						return nil
+					})
+
+					Expect(err).To(Succeed())
+					Expect(count(gateway)).To(Equal(9))
+				})
+			})
+		})
+
+		Describe("Savepoint", func() {
+			It("rollbacks to the savepoint", func() {
+				tx, err := gateway.Begin(ctx)
+				Expect(err).To(Succeed())
+
+				Expect(tx.Savepoint(ctx, "sp1")).To(Succeed())
+				_, err = tx.Exec(ctx, sql.Raw("DELETE FROM users"))
+				Expect(err).To(Succeed())
+				Expect(tx.RollbackTo(ctx, "sp1")).To(Succeed())
+				Expect(tx.Release(ctx, "sp1")).To(Succeed())
+				Expect(tx.Commit()).To(Succeed())
+
+				Expect(count(gateway)).To(Equal(10))
+			})
+
+			Context("when the savepoint is unknown", func() {
+				It("returns an error", func() {
+					tx, err := gateway.Begin(ctx)
+					Expect(err).To(Succeed())
+					Expect(tx.Release(ctx, "sp1")).To(MatchError("orm: exec \"RELEASE SAVEPOINT `sp1`\": no such savepoint: sp1"))
+					Expect(tx.Rollback()).To(Succeed())
+				})
+			})
+		})
+	})
+
+	Describe("WithInterceptor", func() {
+		var queries []*orm.QueryInfo
+
+		BeforeEach(func() {
+			queries = nil
+
+			interceptor := func(ctx context.Context, info *orm.QueryInfo, next orm.Handler) error {
+				err := next(ctx, info)
+				queries = append(queries, info)
+				return err
+			}
+
+			Expect(orm.WithInterceptor(interceptor).Apply(gateway)).To(Succeed())
+		})
+
+		It("intercepts the queries", func() {
+			entities := []*User{}
+			Expect(gateway.All(ctx, sql.Select().From(sql.Table("users")).Where(sql.GT("id", 5)), &entities)).To(Succeed())
+
+			Expect(queries).To(HaveLen(1))
+			Expect(queries[0].Query).To(Equal("SELECT * FROM `users` WHERE `id` > ?"))
+			Expect(queries[0].Args).To(ConsistOf(5))
+			Expect(queries[0].Routine).To(BeEmpty())
+			Expect(queries[0].Rows).NotTo(BeNil())
+		})
+
+		It("intercepts the queries within the transaction", func() {
+			err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+				_, err := tx.Exec(ctx, sql.Raw("DELETE FROM users"))
+				return err
+			})
+
+			Expect(err).To(Succeed())
+			Expect(queries).To(HaveLen(1))
+			Expect(queries[0].Query).To(Equal("DELETE FROM users"))
+			Expect(queries[0].Result.RowsAffected()).To(BeEquivalentTo(10))
+		})
+
+		It("intercepts the routines", func() {
+			_, err := gateway.Exec(ctx, orm.Routine("my-unknown-routine"))
+			Expect(err).To(MatchError(`orm: exec routine "my-unknown-routine": query 'my-unknown-routine' not found`))
+			Expect(queries).To(BeEmpty())
+		})
+
+		It("executes the interceptors in order", func() {
+			order := []string{}
+
+			for _, name := range []string{"first", "second"} {
+				name := name
+
+				interceptor := func(ctx context.Context, info *orm.QueryInfo, next orm.Handler) error {
+					order = append(order, name)
+					return next(ctx, info)
+				}
+
+				Expect(orm.WithInterceptor(interceptor).Apply(gateway)).To(Succeed())
+			}
+
+			_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users"))
+			Expect(err).To(Succeed())
+			Expect(order).To(Equal([]string{"first", "second"}))
+		})
+
+		Context("when the interceptor rejects the query", func() {
+			It("returns an error", func() {
+				interceptor := func(ctx context.Context, info *orm.QueryInfo, next orm.Handler) error {
+					if info.Query == "DROP TABLE users" {
+						return fmt.Errorf("oh no")
+					}
+					return next(ctx, info)
+				}
+
+				Expect(orm.WithInterceptor(interceptor).Apply(gateway)).To(Succeed())
+
+				_, err := gateway.Exec(ctx, sql.Raw("DROP TABLE users"))
+				Expect(err).To(MatchError(`orm: exec "DROP TABLE users": oh no`))
+				Expect(queries).To(HaveLen(1))
+			})
+		})
+
+		Context("when the query fails", func() {
+			It("passes the error to the interceptor", func() {
+				_, err := gateway.Query(ctx, sql.Raw("SELECT * FROM unknown.users"))
+				Expect(err).To(MatchError(`orm: query "SELECT * FROM unknown.users": no such table: unknown.users`))
+				Expect(queries).To(HaveLen(1))
+				Expect(queries[0].Rows).To(BeNil())
+			})
+		})
+	})
+
+	Describe("WithMetrics", func() {
+		It("collects the metrics", func() {
+			collector := &dialect.MemoryCollector{}
+			Expect(orm.WithMetrics(collector).Apply(gateway)).To(Succeed())
+
+			entities := []*User{}
+			Expect(gateway.All(ctx, sql.Raw("SELECT * FROM users WHERE id < 5"), &entities)).To(Succeed())
+
+			stats := collector.Stats("SELECT * FROM users WHERE id < ?")
+			Expect(stats).NotTo(BeNil())
+			Expect(stats.Count).To(BeEquivalentTo(1))
+			Expect(stats.Rows).To(BeEquivalentTo(5))
+		})
+	})
+
+	Describe("WithStmtCacheSize", func() {
+		It("caches the prepared statements of the routines", func() {
+			source := fstest.MapFS{
+				"routine.sql": &fstest.MapFile{
+					Data: []byte(strings.Join([]string{
+						"-- name: count-users",
+						"SELECT COUNT(*) FROM users WHERE id >= :id;",
+						"",
+						"-- name: reset-users",
+						"UPDATE users SET email = NULL WHERE id >= :id; DELETE FROM users WHERE email IS NULL;",
+						"",
+					}, "\n")),
+				},
+			}
+
+			Expect(orm.WithRoutine(source).Apply(gateway)).To(Succeed())
+			Expect(orm.WithStmtCacheSize(1).Apply(gateway)).To(Succeed())
+
+			for i := 0; i < 3; i++ {
+				count, err := orm.Scalar[int](ctx, gateway, orm.Routine("count-users", 5))
+				Expect(err).To(Succeed())
+				Expect(count).To(Equal(5))
+			}
+
+			_, err := gateway.Exec(ctx, orm.Routine("reset-users", 8))
+			Expect(err).To(Succeed())
+
+			count, err := orm.Scalar[int](ctx, gateway, orm.Routine("count-users", 5))
+			Expect(err).To(Succeed())
+			Expect(count).To(Equal(3))
+		})
+	})
+
+	Describe("WithMaxOpenConns", func() {
+		It("configures the driver wrapped by the other options", func() {
+			Expect(orm.WithMetrics(&dialect.MemoryCollector{}).Apply(gateway)).To(Succeed())
+			Expect(orm.WithMaxOpenConns(1).Apply(gateway)).To(Succeed())
+
+			tx, err := gateway.Begin(ctx)
+			Expect(err).To(Succeed())
+
+			// the only connection is used by the transaction
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+
+			_, err = gateway.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(tx.Rollback()).To(Succeed())
+		})
+	})
+
+	Describe("WithRedactedErrors", func() {
+		It("strips the query from the errors", func() {
+			Expect(orm.WithRedactedErrors().Apply(gateway)).To(Succeed())
+
+			entity := &User{}
+			err := gateway.Only(ctx, sql.Raw("SELECT * FROM users WHERE id > 1000"), entity)
+			Expect(err).To(MatchError("orm: only: user not found"))
+			Expect(orm.IsNotFound(err)).To(BeTrue())
+
+			var errx *orm.QueryError
+			Expect(errors.As(err, &errx)).To(BeTrue())
+			Expect(errx.Operation).To(Equal("Only"))
+			Expect(errx.Query).To(BeEmpty())
+		})
+	})
+
+	Describe("WithReplica", func() {
+		var replica *orm.Gateway
+
+		count := func(ctx context.Context, querier orm.Querier) int {
+			value, err := orm.Scalar[int](ctx, querier, sql.Raw("SELECT COUNT(*) FROM users"))
+			Expect(err).To(Succeed())
+			return value
+		}
+
+		BeforeEach(func() {
+			var err error
+
+			replica, err = orm.Open("sqlite3", "file:replica.db?cache=shared&mode=memory")
+			Expect(err).To(BeNil())
+
+			_, err = replica.Exec(ctx, sql.Raw("CREATE TABLE users (id int, first_name varchar(255), last_name varchar(255), email varchar(255), created_at timestamp)"))
+			Expect(err).To(Succeed())
+
+			_, err = replica.Exec(ctx, sql.Raw("INSERT INTO users VALUES (0, 'John', 'Doe', NULL, NULL)"))
+			Expect(err).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(replica.Close()).To(Succeed())
+		})
+
+		It("routes the read queries to the replica", func() {
+			Expect(orm.WithReplica("sqlite3://file:replica.db?cache=shared&mode=memory").Apply(gateway)).To(Succeed())
+			Expect(gateway.Ping(ctx)).To(Succeed())
+
+			Expect(count(ctx, gateway)).To(Equal(1))
+			Expect(count(orm.UsePrimary(ctx), gateway)).To(Equal(10))
+
+			_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users WHERE id = 0"))
+			Expect(err).To(Succeed())
+
+			Expect(count(ctx, gateway)).To(Equal(1))
+			Expect(count(ctx, replica)).To(Equal(1))
+			Expect(count(orm.UsePrimary(ctx), gateway)).To(Equal(9))
+
+			err = gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+				Expect(count(tx.Context(), gateway)).To(Equal(9))
+				return nil
+			})
+
+			Expect(err).To(Succeed())
+		})
+
+		It("routes the read queries to the least used replica", func() {
+			Expect(orm.WithReplicaPolicy(orm.LeastConnections).Apply(gateway)).To(Succeed())
+			Expect(orm.WithReplica("sqlite3://file:replica.db?cache=shared&mode=memory").Apply(gateway)).To(Succeed())
+			Expect(count(ctx, gateway)).To(Equal(1))
+		})
+
+		Context("when the replica fails the ping request", func() {
+			It("ejects the replica", func() {
+				Expect(orm.WithReplicaHealthCheck(time.Hour).Apply(gateway)).To(Succeed())
+				Expect(orm.WithReplica("sqlite3:///unknown/replica.db").Apply(gateway)).To(Succeed())
+				Expect(gateway.Ping(ctx)).To(Succeed())
+
+				Expect(count(ctx, gateway)).To(Equal(10))
+			})
+		})
+
+		Context("when the URL is invalid", func() {
+			It("returns an error", func() {
+				Expect(orm.WithReplica("replica").Apply(gateway)).To(MatchError("invalid dsn"))
+			})
+		})
+	})
+
+	Describe("Prepare", func() {
+		It("executes the statement with different arguments", func() {
+			query := sql.Select().From(sql.Table("users")).Where(sql.EQ("id", sql.Param("id")))
+
+			stmt, err := gateway.Prepare(ctx, query)
+			Expect(err).To(Succeed())
+
+			for i := 0; i < 10; i++ {
+				entity := &User{}
+				Expect(stmt.Only(ctx, entity, orm.Named("id", i))).To(Succeed())
+				Expect(entity.ID).To(Equal(i))
+			}
+		})
+
+		It("executes the mutations", func() {
+			query := sql.Update("users").Set("email", sql.Param("email")).Where(sql.GTE("id", sql.Param("id")))
+
+			stmt, err := gateway.Prepare(ctx, query)
+			Expect(err).To(Succeed())
+
+			result, err := stmt.Exec(ctx, orm.Named("id", 5), orm.Named("email", "root@example.com"))
+			Expect(err).To(Succeed())
+			Expect(result.RowsAffected()).To(BeEquivalentTo(5))
+
+			entities := []*User{}
+			selector := sql.Select().From(sql.Table("users")).Where(sql.EQ("email", sql.Param("email")))
+
+			stmt, err = gateway.Prepare(ctx, selector)
+			Expect(err).To(Succeed())
+			Expect(stmt.All(ctx, &entities, orm.Named("email", "root@example.com"))).To(Succeed())
+			Expect(entities).To(HaveLen(5))
+
+			entity := &User{}
+			Expect(stmt.First(ctx, entity, orm.Named("email", "root@example.com"))).To(Succeed())
+			Expect(entity.ID).To(BeNumerically(">=", 5))
+		})
+
+		It("executes the routines", func() {
+			source := fstest.MapFS{
+				"routine.sql": &fstest.MapFile{
+					Data: []byte("-- name: find-user\nSELECT * FROM users WHERE id = :id;\n"),
+				},
+			}
+
+			Expect(orm.WithRoutine(source).Apply(gateway)).To(Succeed())
+
+			stmt, err := gateway.Prepare(ctx, orm.Routine("find-user", orm.Param("id")))
+			Expect(err).To(Succeed())
+
+			entity := &User{}
+			Expect(stmt.Only(ctx, entity, orm.Named("id", 7))).To(Succeed())
+			Expect(entity.ID).To(Equal(7))
+		})
+
+		Context("when the argument is missing", func() {
+			It("returns an error", func() {
+				query := sql.Select().From(sql.Table("users")).Where(sql.EQ("id", sql.Param("id")))
+
+				stmt, err := gateway.Prepare(ctx, query)
+				Expect(err).To(Succeed())
+
+				entity := &User{}
+				err = stmt.Only(ctx, entity)
+				Expect(err).To(MatchError(`orm: only "SELECT * FROM ` + "`users`" + ` WHERE ` + "`id`" + ` = ?": dialect/sql: parameter "id" is not bound`))
+
+				var errx *orm.QueryError
+				Expect(errors.As(err, &errx)).To(BeTrue())
+				Expect(errx.Operation).To(Equal("Only"))
+				Expect(errx.NumArgs).To(Equal(1))
+			})
+		})
+
+		Context("when the routine is unknown", func() {
+			It("returns an error", func() {
+				stmt, err := gateway.Prepare(ctx, orm.Routine("my-unknown-routine"))
+				Expect(err).To(MatchError(`orm: prepare routine "my-unknown-routine": query 'my-unknown-routine' not found`))
+				Expect(stmt).To(BeNil())
+			})
+		})
+	})
+
 	Describe("Dialect", func() {
 		It("returns the dialect", func() {
 			Expect(gateway.Dialect()).To(Equal("sqlite3"))
 		})
 	})
 
+	Describe("Inspect", func() {
+		It("returns the schema", func() {
+			value, err := gateway.Inspect(ctx)
+			Expect(err).To(BeNil())
+
+			table := value.Table("users")
+			Expect(table).NotTo(BeNil())
+			Expect(table.PrimaryKey).To(Equal([]string{"id"}))
+			Expect(table.Columns).To(HaveLen(5))
+			Expect(table.Column("email").Nullable).To(BeTrue())
+			Expect(table.Column("first_name").Nullable).To(BeFalse())
+		})
+	})
+
+	Describe("Diff", func() {
+		It("returns the plan", func() {
+			current, err := gateway.Inspect(ctx)
+			Expect(err).To(BeNil())
+
+			table := current.Table("users")
+			table.Indexes = append(table.Indexes, &schema.Index{
+				Name:    "users_email",
+				Columns: []string{"email"},
+			})
+
+			plan, err := gateway.Diff(ctx, current, nil)
+			Expect(err).To(BeNil())
+			Expect(plan.Statements()).To(Equal([]string{
+				"CREATE INDEX `users_email` ON `users`(`email`)",
+			}))
+		})
+	})
+
+	Describe("WithRoutine", func() {
+		It("executes the variant of the dialect", func() {
+			source := fstest.MapFS{
+				"routine.sql": &fstest.MapFile{
+					Data: []byte("-- name: count-users\nSELECT COUNT(*) FROM users WHERE id >= :id;\n"),
+				},
+				"routine.postgres.sql": &fstest.MapFile{
+					Data: []byte("-- name: count-users\nSELECT COUNT(*) FROM users WHERE id >= :id::int;\n"),
+				},
+				"users.sql": &fstest.MapFile{
+					Data: []byte(strings.Join([]string{
+						"-- name: first-user",
+						"-- dialect: sqlite3",
+						"SELECT MIN(id) FROM users;",
+						"",
+						"-- name: first-user",
+						"-- dialect: mysql",
+						"SELECT MIN(`id`) FROM `users`;",
+						"",
+					}, "\n")),
+				},
+			}
+
+			Expect(orm.WithRoutine(source).Apply(gateway)).To(Succeed())
+
+			count, err := orm.Scalar[int](ctx, gateway, orm.Routine("count-users", 5))
+			Expect(err).To(Succeed())
+			Expect(count).To(Equal(5))
+
+			id, err := orm.Scalar[int](ctx, gateway, orm.Routine("first-user"))
+			Expect(err).To(Succeed())
+			Expect(id).To(Equal(0))
+		})
+	})
+
+	Describe("ValidateRoutines", func() {
+		It("validates the routines", func() {
+			source := fstest.MapFS{
+				"routine.sql": &fstest.MapFile{
+					Data: []byte(strings.Join([]string{
+						"-- name: find-user",
+						"SELECT * FROM users WHERE id = :id AND email = :email OR id = :id;",
+						"",
+						"-- name: find-users",
+						"SELECT * FROM users WHERE id IN (:ids);",
+						"",
+					}, "\n")),
+				},
+				"routine.postgres.sql": &fstest.MapFile{
+					Data: []byte("-- name: broken-user\nSELECT * FROM users WHERE email = ':email;\n"),
+				},
+			}
+
+			Expect(orm.WithRoutine(source).Apply(gateway)).To(Succeed())
+			Expect(gateway.ValidateRoutines(ctx)).To(Succeed())
+			Expect(gateway.ValidateRoutinesWith(ctx, &orm.ValidateOptions{Prepare: true})).To(Succeed())
+		})
+
+		Context("when the named parameters cannot be parsed", func() {
+			It("returns an error", func() {
+				source := fstest.MapFS{
+					"routine.sql": &fstest.MapFile{
+						Data: []byte(strings.Join([]string{
+							"-- name: find-user",
+							"SELECT * FROM users WHERE email = ':email;",
+							"",
+							"-- name: find-users",
+							"SELECT * FROM users /* WHERE id = :id;",
+							"",
+						}, "\n")),
+					},
+				}
+
+				Expect(orm.WithRoutine(source).Apply(gateway)).To(Succeed())
+
+				err := gateway.ValidateRoutines(ctx)
+				Expect(err).To(MatchError(ContainSubstring(`orm: validate routine "find-user": dialect: unterminated quoted string at position 34`)))
+				Expect(err).To(MatchError(ContainSubstring(`orm: validate routine "find-users": dialect: unterminated block comment at position 20`)))
+
+				var errx *orm.QueryError
+				Expect(errors.As(err, &errx)).To(BeTrue())
+				Expect(errx.Operation).To(Equal("Validate"))
+			})
+		})
+
+		Context("when the routine cannot be prepared", func() {
+			It("returns an error", func() {
+				source := fstest.MapFS{
+					"routine.sql": &fstest.MapFile{
+						Data: []byte("-- name: find-account\nSELECT * FROM accounts WHERE id = :id;\n"),
+					},
+				}
+
+				Expect(orm.WithRoutine(source).Apply(gateway)).To(Succeed())
+				Expect(gateway.ValidateRoutines(ctx)).To(Succeed())
+
+				err := gateway.ValidateRoutinesWith(ctx, &orm.ValidateOptions{Prepare: true})
+				Expect(err).To(MatchError(`orm: validate routine "find-account": no such table: accounts`))
+			})
+		})
+	})
+
 	Describe("All", func() {
+		It("expands the slice parameters", func() {
+			entities := []*User{}
+
+			query := orm.Query("SELECT * FROM users WHERE id IN (:ids) ORDER BY id", sql.Named("ids", []int{2, 4, 6}))
+			Expect(gateway.All(ctx, query, &entities)).To(Succeed())
+			Expect(entities).To(HaveLen(3))
+			Expect(entities[0].ID).To(Equal(2))
+			Expect(entities[2].ID).To(Equal(6))
+		})
+
+		Context("when the slice parameter is empty", func() {
+			It("returns an error", func() {
+				entities := []*User{}
+
+				query := orm.Query("SELECT * FROM users WHERE id IN (:ids)", sql.Named("ids", []int{}))
+				Expect(gateway.All(ctx, query, &entities)).To(MatchError(ContainSubstring(`dialect/sql: parameter "ids" is an empty slice`)))
+			})
+		})
+
 		It("returns all entities", func() {
 			entities := []*User{}
 
@@ -195,7 +1095,7 @@ var _ = Describe("Gateway", func() {
 			It("returns an error", func() {
 				entities := []*User{}
 
-				Expect(gateway.All(ctx, sql.Raw("SELECT * FROM unknown.users"), &entities)).To(MatchError("no such table: unknown.users"))
+				Expect(gateway.All(ctx, sql.Raw("SELECT * FROM unknown.users"), &entities)).To(MatchError(`orm: all "SELECT * FROM unknown.users": no such table: unknown.users`))
 				Expect(entities).To(HaveLen(0))
 			})
 		})
@@ -205,7 +1105,7 @@ var _ = Describe("Gateway", func() {
 				entities := []*User{}
 
 				err := gateway.All(ctx, sql.Routine("my-unknown-routine"), entities)
-				Expect(err).To(MatchError("query 'my-unknown-routine' not found"))
+				Expect(err).To(MatchError(`orm: all routine "my-unknown-routine": query 'my-unknown-routine' not found`))
 			})
 		})
 	})
@@ -222,28 +1122,28 @@ var _ = Describe("Gateway", func() {
 		Context("when the provided type is not compatible", func() {
 			It("returns an error", func() {
 				entity := "root"
-				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM users WHERE id = 0"), &entity)).To(MatchError("sql/scan: columns do not match (5 > 1)"))
+				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM users WHERE id = 0"), &entity)).To(MatchError(`orm: only "SELECT * FROM users WHERE id = 0": sql/scan: columns do not match (5 > 1)`))
 			})
 		})
 
 		Context("when there are more than one entities", func() {
 			It("returns an error", func() {
 				entity := &User{}
-				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM users"), entity)).To(MatchError("orm: user not singular"))
+				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM users"), entity)).To(MatchError(`orm: only "SELECT * FROM users": user not singular`))
 			})
 		})
 
 		Context("when there are not entities", func() {
 			It("returns an error", func() {
 				entity := &User{}
-				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM users WHERE id > 1000"), entity)).To(MatchError("orm: user not found"))
+				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM users WHERE id > 1000"), entity)).To(MatchError(`orm: only "SELECT * FROM users WHERE id > 1000": user not found`))
 			})
 		})
 
 		Context("when the database operation fail", func() {
 			It("returns an error", func() {
 				entity := &User{}
-				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM unknown.users"), entity)).To(MatchError("no such table: unknown.users"))
+				Expect(gateway.Only(ctx, sql.Raw("SELECT * FROM unknown.users"), entity)).To(MatchError(`orm: only "SELECT * FROM unknown.users": no such table: unknown.users`))
 			})
 		})
 
@@ -251,7 +1151,7 @@ var _ = Describe("Gateway", func() {
 			It("returns an error", func() {
 				entity := &User{}
 				err := gateway.Only(ctx, sql.Routine("my-unknown-routine"), entity)
-				Expect(err).To(MatchError("query 'my-unknown-routine' not found"))
+				Expect(err).To(MatchError(`orm: only routine "my-unknown-routine": query 'my-unknown-routine' not found`))
 			})
 		})
 	})
@@ -268,21 +1168,21 @@ var _ = Describe("Gateway", func() {
 		Context("when the provided type is not compatible", func() {
 			It("returns an error", func() {
 				entity := "root"
-				Expect(gateway.First(ctx, sql.Raw("SELECT * FROM users WHERE id = 0"), &entity)).To(MatchError("sql/scan: columns do not match (5 > 1)"))
+				Expect(gateway.First(ctx, sql.Raw("SELECT * FROM users WHERE id = 0"), &entity)).To(MatchError(`orm: first "SELECT * FROM users WHERE id = 0": sql/scan: columns do not match (5 > 1)`))
 			})
 		})
 
 		Context("when there are not entities", func() {
 			It("returns an error", func() {
 				entity := &User{}
-				Expect(gateway.First(ctx, sql.Raw("SELECT * FROM users WHERE id > 1000"), entity)).To(MatchError("orm: user not found"))
+				Expect(gateway.First(ctx, sql.Raw("SELECT * FROM users WHERE id > 1000"), entity)).To(MatchError(`orm: first "SELECT * FROM users WHERE id > 1000": user not found`))
 			})
 		})
 
 		Context("when the database operation fail", func() {
 			It("returns an error", func() {
 				entity := &User{}
-				Expect(gateway.First(ctx, sql.Raw("SELECT * FROM unknown.users"), entity)).To(MatchError("no such table: unknown.users"))
+				Expect(gateway.First(ctx, sql.Raw("SELECT * FROM unknown.users"), entity)).To(MatchError(`orm: first "SELECT * FROM unknown.users": no such table: unknown.users`))
 			})
 		})
 
@@ -290,24 +1190,149 @@ var _ = Describe("Gateway", func() {
 			It("returns an error", func() {
 				entity := &User{}
 				err := gateway.First(ctx, sql.Routine("my-unknown-routine"), entity)
-				Expect(err).To(MatchError("query 'my-unknown-routine' not found"))
+				Expect(err).To(MatchError(`orm: first routine "my-unknown-routine": query 'my-unknown-routine' not found`))
+			})
+		})
+	})
+
+	Describe("Iterate", func() {
+		It("returns a cursor", func() {
+			cursor, err := gateway.Iterate(ctx, sql.Raw("SELECT * FROM users ORDER BY id"))
+			Expect(err).To(Succeed())
+			defer cursor.Close()
+
+			count := 0
+
+			for cursor.Next() {
+				entity := &User{}
+				Expect(cursor.Scan(entity)).To(Succeed())
+				Expect(entity.ID).To(Equal(count))
+				count++
+			}
+
+			Expect(cursor.Err()).To(Succeed())
+			Expect(count).To(Equal(10))
+		})
+
+		Context("when the database operation fail", func() {
+			It("returns an error", func() {
+				cursor, err := gateway.Iterate(ctx, sql.Raw("SELECT * FROM unknown.users"))
+				Expect(err).To(MatchError(`orm: iterate "SELECT * FROM unknown.users": no such table: unknown.users`))
+				Expect(cursor).To(BeNil())
 			})
 		})
 	})
 
 	Describe("Exec", func() {
+		It("truncates the table", func() {
+			_, err := gateway.Exec(ctx, sql.Truncate("users"))
+			Expect(err).To(Succeed())
+
+			entities := []*User{}
+			Expect(gateway.All(ctx, sql.Select().From(sql.Table("users")), &entities)).To(Succeed())
+			Expect(entities).To(BeEmpty())
+		})
+
 		Context("when the query has wrong syntax", func() {
 			It("returns an error", func() {
 				_, err := gateway.Exec(ctx, sql.Raw("SELECT * FROM unknown.users"))
-				Expect(err).To(MatchError("no such table: unknown.users"))
+				Expect(err).To(MatchError(`orm: exec "SELECT * FROM unknown.users": no such table: unknown.users`))
 			})
 		})
 
 		Context("when the routine is unknown", func() {
 			It("returns an error", func() {
 				_, err := gateway.Exec(ctx, sql.Routine("my-unknown-routine"))
-				Expect(err).To(MatchError("query 'my-unknown-routine' not found"))
+				Expect(err).To(MatchError(`orm: exec routine "my-unknown-routine": query 'my-unknown-routine' not found`))
+			})
+		})
+
+		Context("when the query fails", func() {
+			It("returns a query error", func() {
+				query := sql.Insert("users").Columns("id", "first_name", "last_name").Values(1, "John", "Doe")
+
+				_, err := gateway.Exec(ctx, query)
+				Expect(err).To(MatchError("orm: exec \"INSERT INTO `users` (`id`, `first_name`, `last_name`) VALUES (?, ?, ?)\": " +
+					"constraint violation: UNIQUE constraint failed: users.id"))
+
+				var errx *orm.QueryError
+				Expect(errors.As(err, &errx)).To(BeTrue())
+				Expect(errx.Operation).To(Equal("Exec"))
+				Expect(errx.Routine).To(BeEmpty())
+				Expect(errx.Query).To(Equal("INSERT INTO `users` (`id`, `first_name`, `last_name`) VALUES (?, ?, ?)"))
+				Expect(errx.NumArgs).To(Equal(3))
+			})
+		})
+
+		Context("when the query violates a constraint", func() {
+			It("returns a database error", func() {
+				query := sql.Insert("users").Columns("id", "first_name", "last_name").Values(1, "John", "Doe")
+
+				_, err := gateway.Exec(ctx, query)
+				Expect(orm.IsConstraintViolation(err)).To(BeTrue())
+				Expect(orm.IsUniqueViolation(err)).To(BeTrue())
+
+				var errx *orm.DatabaseError
+				Expect(errors.As(err, &errx)).To(BeTrue())
+				Expect(errx.Table).To(Equal("users"))
+				Expect(errx.Column).To(Equal("id"))
+
+				query = sql.Insert("users").Columns("id", "last_name").Values(100, "Doe")
+
+				_, err = gateway.Exec(ctx, query)
+				Expect(orm.IsNotNullViolation(err)).To(BeTrue())
 			})
 		})
 	})
 })
+
+var _ = Describe("Gateway Migration", func() {
+	var (
+		gateway *orm.Gateway
+		storage fstest.MapFS
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		gateway, err = orm.Open("sqlite3", "file:migration.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		storage = fstest.MapFS{
+			"00060524000000_setup.sql": &fstest.MapFile{
+				Data: []byte("-- name: up\nCREATE TABLE migrations (id TEXT NOT NULL PRIMARY KEY, description TEXT NOT NULL, created_at TIMESTAMP NOT NULL);\n\n-- name: down\nDROP TABLE migrations;\n"),
+			},
+			"20180406190015_users.sql": &fstest.MapFile{
+				Data: []byte("-- name: up\nCREATE TABLE users (id INT PRIMARY KEY NOT NULL);\n\n-- name: down\nDROP TABLE users;\n"),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(gateway.Rollback(storage, -1)).To(Succeed())
+		Expect(gateway.Close()).To(Succeed())
+	})
+
+	It("migrates the database to the version", func() {
+		Expect(gateway.MigrateTo(storage, "00060524000000")).To(Succeed())
+
+		items, err := gateway.MigrationStatus(storage)
+		Expect(err).To(BeNil())
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].Pending()).To(BeFalse())
+		Expect(items[1].Pending()).To(BeTrue())
+
+		items, err = gateway.MigrateToWith(storage, "20180406190015", &orm.MigrateOptions{DryRun: true})
+		Expect(err).To(BeNil())
+		Expect(items).To(HaveLen(1))
+		Expect(items[0].Statements).To(Equal([]string{"CREATE TABLE users (id INT PRIMARY KEY NOT NULL);"}))
+
+		Expect(gateway.MigrateTo(storage, "20180406190015")).To(Succeed())
+		Expect(gateway.Rollback(storage, 1)).To(Succeed())
+
+		items, err = gateway.RollbackWith(storage, 1, &orm.MigrateOptions{DryRun: true})
+		Expect(err).To(BeNil())
+		Expect(items).To(HaveLen(1))
+		Expect(items[0].Statements).To(Equal([]string{"DROP TABLE migrations;"}))
+	})
+})
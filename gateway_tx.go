@@ -2,7 +2,10 @@ package orm
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 
+	"github.com/phogolabs/log"
 	"github.com/phogolabs/orm/dialect"
 	"github.com/phogolabs/orm/dialect/sql"
 )
@@ -14,7 +17,17 @@ type RunTxFunc func(gateway *GatewayTx) error
 
 // GatewayTx represents a gateway in transaction
 type GatewayTx struct {
-	engine *engine
+	ctx       context.Context
+	gateway   *Gateway
+	engine    *engine
+	savepoint int64
+}
+
+// Context returns a context that carries the transaction. The queries
+// executed by the gateway that started the transaction with that context
+// run within the transaction.
+func (g *GatewayTx) Context() context.Context {
+	return g.ctx
 }
 
 // All executes the query and returns a list of entities.
@@ -34,6 +47,12 @@ func (g *GatewayTx) First(ctx context.Context, q sql.Querier, v interface{}) err
 	return g.engine.First(ctx, q, v)
 }
 
+// Iterate executes the query and returns a cursor that reads the entities
+// one by one. The cursor must be closed by the caller.
+func (g *GatewayTx) Iterate(ctx context.Context, q sql.Querier) (*Cursor, error) {
+	return g.engine.Iterate(ctx, q)
+}
+
 // Query executes a query that returns rows, typically a SELECT in SQL.
 // It scans the result into the pointer v. In SQL, you it's usually *sql.Rows.
 func (g *GatewayTx) Query(ctx context.Context, q sql.Querier) (*sql.Rows, error) {
@@ -58,3 +77,58 @@ func (g *GatewayTx) Rollback() error {
 	tx := g.engine.querier.(dialect.Tx)
 	return tx.Rollback()
 }
+
+// Savepoint establishes a savepoint with the given name within the
+// transaction.
+func (g *GatewayTx) Savepoint(ctx context.Context, name string) error {
+	_, err := g.engine.Exec(ctx, sql.Savepoint(name))
+	return err
+}
+
+// RollbackTo rollbacks all changes made after the savepoint with the given
+// name was established. The transaction and the savepoint remain active.
+func (g *GatewayTx) RollbackTo(ctx context.Context, name string) error {
+	_, err := g.engine.Exec(ctx, sql.RollbackToSavepoint(name))
+	return err
+}
+
+// Release releases the savepoint with the given name. The changes made after
+// the savepoint remain part of the transaction.
+func (g *GatewayTx) Release(ctx context.Context, name string) error {
+	_, err := g.engine.Exec(ctx, sql.ReleaseSavepoint(name))
+	return err
+}
+
+// RunInTx runs a callback function within a nested transaction backed by a
+// savepoint. It releases the savepoint if succeeds, otherwise rollbacks to
+// it. The outer transaction continues in both cases.
+func (g *GatewayTx) RunInTx(ctx context.Context, fn RunTxFunc) error {
+	var (
+		logger = log.GetContext(ctx)
+		name   = fmt.Sprintf("orm_savepoint_%d", atomic.AddInt64(&g.savepoint, 1))
+	)
+
+	if err := g.Savepoint(ctx, name); err != nil {
+		return err
+	}
+
+	if err := fn(g); err != nil {
+		if rerr := g.RollbackTo(ctx, name); rerr != nil {
+			logger.WithError(rerr).Error("cannot rollback to savepoint")
+			return err
+		}
+
+		if rerr := g.Release(ctx, name); rerr != nil {
+			logger.WithError(rerr).Error("cannot release savepoint")
+		}
+
+		return err
+	}
+
+	if rerr := g.Release(ctx, name); rerr != nil {
+		logger.WithError(rerr).Error("cannot release savepoint")
+		return rerr
+	}
+
+	return nil
+}
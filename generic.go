@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"context"
+	"iter"
+
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// All executes the query and returns a list of entities of type T.
+func All[T any](ctx context.Context, querier Querier, q sql.Querier) ([]T, error) {
+	entities := []T{}
+
+	if err := querier.All(ctx, q, &entities); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// Only returns the only entity of type T in the query, returns an error if
+// not exactly one entity was returned.
+func Only[T any](ctx context.Context, querier Querier, q sql.Querier) (T, error) {
+	var entity T
+
+	if err := querier.Only(ctx, q, &entity); err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return entity, nil
+}
+
+// First returns the first entity of type T in the query. Returns
+// *NotFoundError when no records were found.
+func First[T any](ctx context.Context, querier Querier, q sql.Querier) (T, error) {
+	var entity T
+
+	if err := querier.First(ctx, q, &entity); err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return entity, nil
+}
+
+// Scalar returns the value of the single column of the first row in the
+// query. It's usually used for aggregations like COUNT or MAX. Returns
+// *NotFoundError when no records were found.
+func Scalar[T any](ctx context.Context, querier Querier, q sql.Querier) (T, error) {
+	return First[T](ctx, querier, q)
+}
+
+// Iterate executes the query and returns a sequence that decodes one entity
+// of type T per step. The underlying rows are closed when the iteration
+// completes or stops early. Any error terminates the sequence.
+func Iterate[T any](ctx context.Context, querier Querier, q sql.Querier) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var empty T
+
+		cursor, err := querier.Iterate(ctx, q)
+		if err != nil {
+			yield(empty, err)
+			return
+		}
+		// close the cursor
+		defer cursor.Close()
+
+		for cursor.Next() {
+			var entity T
+
+			if err := cursor.Scan(&entity); err != nil {
+				yield(empty, err)
+				return
+			}
+
+			if !yield(entity, nil) {
+				return
+			}
+		}
+
+		if err := cursor.Err(); err != nil {
+			yield(empty, err)
+		}
+	}
+}
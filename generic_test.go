@@ -0,0 +1,202 @@
+package orm_test
+
+import (
+	"context"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/phogolabs/orm"
+	"github.com/phogolabs/orm/dialect/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generic", func() {
+	var (
+		ctx     context.Context
+		gateway *orm.Gateway
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		gateway, err = orm.Open("sqlite3", "file:test.db?cache=shared&mode=memory")
+		Expect(err).To(BeNil())
+
+		ctx = context.TODO()
+
+		query :=
+			sql.CreateTable("users").IfNotExists().
+				Columns(
+					sql.Column("id").Type("int"),
+					sql.Column("first_name").Type("varchar(255)").Attr("NOT NULL"),
+					sql.Column("last_name").Type("varchar(255)").Attr("NOT NULL"),
+					sql.Column("email").Type("varchar(255)").Attr("NULL"),
+					sql.Column("created_at").Type("timestamp").Attr("NULL"),
+				).
+				PrimaryKey("id")
+
+		_, err = gateway.Exec(ctx, query)
+		Expect(err).To(Succeed())
+
+		for i := 0; i < 10; i++ {
+			query :=
+				sql.Insert("users").
+					Columns("id", "first_name", "last_name", "email").
+					Values(i, faker.FirstName(), faker.LastName(), faker.Email())
+
+			_, err := gateway.Exec(ctx, query)
+			Expect(err).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		_, err := gateway.Exec(ctx, sql.Raw("DELETE FROM users"))
+		Expect(err).To(Succeed())
+
+		Expect(gateway.Close()).To(Succeed())
+	})
+
+	Describe("All", func() {
+		It("returns all entities", func() {
+			entities, err := orm.All[*User](ctx, gateway, sql.Raw("SELECT * FROM users"))
+			Expect(err).To(Succeed())
+			Expect(entities).To(HaveLen(10))
+			Expect(entities[0].ID).To(Equal(0))
+			Expect(entities[9].ID).To(Equal(9))
+		})
+
+		It("returns all values", func() {
+			entities, err := orm.All[User](ctx, gateway, sql.Raw("SELECT * FROM users"))
+			Expect(err).To(Succeed())
+			Expect(entities).To(HaveLen(10))
+			Expect(entities[0].Email).NotTo(BeNil())
+		})
+
+		Context("when the database operation fail", func() {
+			It("returns an error", func() {
+				entities, err := orm.All[*User](ctx, gateway, sql.Raw("SELECT * FROM unknown.users"))
+				Expect(err).To(MatchError(`orm: all "SELECT * FROM unknown.users": no such table: unknown.users`))
+				Expect(entities).To(BeNil())
+			})
+		})
+
+		Context("when the querier is a transaction", func() {
+			It("returns all entities", func() {
+				err := gateway.RunInTx(ctx, func(tx *orm.GatewayTx) error {
+					entities, err := orm.All[*User](ctx, tx, sql.Raw("SELECT * FROM users"))
+					Expect(entities).To(HaveLen(10))
+					return err
+				})
+
+				Expect(err).To(Succeed())
+			})
+		})
+	})
+
+	Describe("Only", func() {
+		It("returns the only entity", func() {
+			entity, err := orm.Only[*User](ctx, gateway, sql.Raw("SELECT * FROM users WHERE id = 1"))
+			Expect(err).To(Succeed())
+			Expect(entity).NotTo(BeNil())
+			Expect(entity.ID).To(Equal(1))
+		})
+
+		Context("when there are more than one entities", func() {
+			It("returns an error", func() {
+				entity, err := orm.Only[*User](ctx, gateway, sql.Raw("SELECT * FROM users"))
+				Expect(err).To(MatchError(`orm: only "SELECT * FROM users": user not singular`))
+				Expect(entity).To(BeNil())
+			})
+		})
+
+		Context("when there are not entities", func() {
+			It("returns an error", func() {
+				entity, err := orm.Only[*User](ctx, gateway, sql.Raw("SELECT * FROM users WHERE id > 1000"))
+				Expect(err).To(MatchError(`orm: only "SELECT * FROM users WHERE id > 1000": user not found`))
+				Expect(entity).To(BeNil())
+			})
+		})
+	})
+
+	Describe("First", func() {
+		It("returns the first entity", func() {
+			entity, err := orm.First[User](ctx, gateway, sql.Raw("SELECT * FROM users ORDER BY id DESC"))
+			Expect(err).To(Succeed())
+			Expect(entity.ID).To(Equal(9))
+		})
+
+		Context("when there are not entities", func() {
+			It("returns an error", func() {
+				entity, err := orm.First[*User](ctx, gateway, sql.Raw("SELECT * FROM users WHERE id > 1000"))
+				Expect(orm.IsNotFound(err)).To(BeTrue())
+				Expect(entity).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Scalar", func() {
+		It("returns the value", func() {
+			count, err := orm.Scalar[int](ctx, gateway, sql.Raw("SELECT COUNT(*) FROM users"))
+			Expect(err).To(Succeed())
+			Expect(count).To(Equal(10))
+		})
+
+		Context("when the query returns more than one column", func() {
+			It("returns an error", func() {
+				_, err := orm.Scalar[int](ctx, gateway, sql.Raw("SELECT id, email FROM users"))
+				Expect(err).To(MatchError(`orm: first "SELECT id, email FROM users": sql/scan: columns do not match (2 > 1)`))
+			})
+		})
+	})
+
+	Describe("Iterate", func() {
+		It("returns all entities", func() {
+			entities := []*User{}
+
+			for entity, err := range orm.Iterate[*User](ctx, gateway, sql.Raw("SELECT * FROM users ORDER BY id")) {
+				Expect(err).To(Succeed())
+				entities = append(entities, entity)
+			}
+
+			Expect(entities).To(HaveLen(10))
+			Expect(entities[0].ID).To(Equal(0))
+			Expect(entities[9].ID).To(Equal(9))
+		})
+
+		Context("when the iteration stops early", func() {
+			It("releases the connection", func() {
+				for entity, err := range orm.Iterate[*User](ctx, gateway, sql.Raw("SELECT * FROM users ORDER BY id")) {
+					Expect(err).To(Succeed())
+					Expect(entity.ID).To(Equal(0))
+					break
+				}
+
+				_, err := gateway.Exec(ctx, sql.Raw("UPDATE users SET email = NULL"))
+				Expect(err).To(Succeed())
+			})
+		})
+
+		Context("when the database operation fail", func() {
+			It("returns an error", func() {
+				count := 0
+
+				for entity, err := range orm.Iterate[*User](ctx, gateway, sql.Raw("SELECT * FROM unknown.users")) {
+					Expect(err).To(MatchError(`orm: iterate "SELECT * FROM unknown.users": no such table: unknown.users`))
+					Expect(entity).To(BeNil())
+					count++
+				}
+
+				Expect(count).To(Equal(1))
+			})
+		})
+
+		Context("when the type is not compatible", func() {
+			It("returns an error", func() {
+				for _, err := range orm.Iterate[int](ctx, gateway, sql.Raw("SELECT * FROM users")) {
+					Expect(err).To(MatchError("sql/scan: columns do not match (5 > 1)"))
+				}
+			})
+		})
+	})
+})
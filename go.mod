@@ -23,6 +23,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fatih/color v1.19.0 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-playground/ansi v2.1.0+incompatible // indirect
 	github.com/go-sql-driver/mysql v1.9.3 // indirect
 	github.com/go-task/slim-sprig/v3 v3.0.0 // indirect
 	github.com/goccy/go-json v0.10.6 // indirect
@@ -38,6 +39,7 @@ require (
 	github.com/olekukonko/tablewriter v1.1.4 // indirect
 	github.com/phogolabs/flaw v0.0.0-20230111045222-8efffb46800b // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rollbar/rollbar-go v1.4.5 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/mod v0.35.0 // indirect
 	golang.org/x/net v0.53.0 // indirect
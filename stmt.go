@@ -0,0 +1,89 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/phogolabs/orm/dialect/sql"
+)
+
+// Stmt is a prepared statement. It's compiled once and executed many times
+// with different named arguments that are bound to the placeholders created
+// by sql.Param. The statement is safe for concurrent use.
+type Stmt struct {
+	gateway *Gateway
+	routine string
+	query   string
+	args    []interface{}
+}
+
+// All executes the statement with the given arguments and returns a list of
+// entities.
+func (s *Stmt) All(ctx context.Context, v interface{}, args ...NamedArg) error {
+	query, err := s.bind("All", args)
+	if err != nil {
+		return err
+	}
+
+	return s.gateway.All(ctx, query, v)
+}
+
+// Only executes the statement with the given arguments and returns the only
+// entity, returns an error if not exactly one entity was returned.
+func (s *Stmt) Only(ctx context.Context, v interface{}, args ...NamedArg) error {
+	query, err := s.bind("Only", args)
+	if err != nil {
+		return err
+	}
+
+	return s.gateway.Only(ctx, query, v)
+}
+
+// First executes the statement with the given arguments and returns the first
+// entity. Returns *NotFoundError when no records were found.
+func (s *Stmt) First(ctx context.Context, v interface{}, args ...NamedArg) error {
+	query, err := s.bind("First", args)
+	if err != nil {
+		return err
+	}
+
+	return s.gateway.First(ctx, query, v)
+}
+
+// Exec executes the statement with the given arguments. The statement
+// doesn't return rows. For example, in SQL, INSERT or UPDATE.
+func (s *Stmt) Exec(ctx context.Context, args ...NamedArg) (sql.Result, error) {
+	query, err := s.bind("Exec", args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.gateway.Exec(ctx, query)
+}
+
+// bind binds the named arguments to the placeholders of the statement. The
+// error is reported as a *QueryError of the given operation.
+func (s *Stmt) bind(operation string, values []NamedArg) (*stmtQuery, error) {
+	args, err := sql.Bind(s.args, values...)
+	if err != nil {
+		info := &QueryInfo{
+			Routine: s.routine,
+			Query:   s.query,
+			Args:    s.args,
+		}
+
+		return nil, s.gateway.engine.fail(operation, info, err)
+	}
+
+	return &stmtQuery{stmt: s, args: args}, nil
+}
+
+// stmtQuery is a compiled query with bound arguments.
+type stmtQuery struct {
+	stmt *Stmt
+	args []interface{}
+}
+
+// Query returns the compiled query and the bound arguments.
+func (q *stmtQuery) Query() (string, []interface{}) {
+	return q.stmt.query, q.args
+}